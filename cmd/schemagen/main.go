@@ -0,0 +1,58 @@
+// Command schemagen writes the JSON Schema of the configuration file format.
+// Run it from the repository root:
+//
+//	go run ./cmd/schemagen -o config.schema.json
+//
+// With -check it compares the schema with the file instead and exits with
+// status 1 when the file is out of date.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hadi/ssl-cert-monitor/internal/schema"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/config", "Directory of the config package, for descriptions")
+	output := flag.String("o", "", "File to write the schema to (default stdout)")
+	check := flag.Bool("check", false, "Exit with status 1 if the -o file is not up to date")
+	flag.Parse()
+
+	docs, err := schema.LoadDocs(*dir)
+	if err != nil {
+		fatal(err)
+	}
+	data, err := schema.Generate(docs)
+	if err != nil {
+		fatal(err)
+	}
+
+	switch {
+	case *check:
+		if *output == "" {
+			fatal(fmt.Errorf("-check requires -o"))
+		}
+		current, err := os.ReadFile(*output)
+		if err != nil {
+			fatal(err)
+		}
+		if !bytes.Equal(current, data) {
+			fatal(fmt.Errorf("%s is out of date, run make schema", *output))
+		}
+	case *output != "":
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			fatal(err)
+		}
+	default:
+		os.Stdout.Write(data)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "schemagen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,47 @@
+// Command validate loads a configuration file and runs every check of
+// startup without sending notifications or touching the state file:
+//
+//	go run ./cmd/validate -config config.yaml
+//
+// Each problem is printed on its own line and the exit status is 1 if
+// there are any, so it can run in CI before a configuration is deployed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hadi/ssl-cert-monitor/internal/engine"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run validates the config file named by args and returns the exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	path := flags.String("config", "config.yaml", "Configuration file to validate")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "validate: unexpected arguments %q\n", flags.Args())
+		return 2
+	}
+
+	if err := engine.ValidateFile(*path); err != nil {
+		problems := strings.Split(err.Error(), "\n")
+		for _, problem := range problems {
+			fmt.Fprintln(stderr, problem)
+		}
+		fmt.Fprintf(stderr, "validate: %s has %d problem(s)\n", *path, len(problems))
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s is valid\n", *path)
+	return 0
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunValid(t *testing.T) {
+	path := writeConfig(t, `
+domains:
+  - host: example.com
+notifications:
+  webhook:
+    enabled: true
+    url: https://hooks.example.com
+`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d, stderr:\n%s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "is valid") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
+
+func TestRunReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, `
+domains:
+  - host: a.example.com
+    prot: 443
+  - host: b.example.com
+    port: 70000
+routes:
+  - notifiers: [ghost]
+`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, want 1", code)
+	}
+	for _, want := range []string{
+		`config.yaml:4: unknown field "prot"`,
+		"domain b.example.com has invalid port 70000",
+		"has 2 problem(s)",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr does not mention %q:\n%s", want, stderr.String())
+		}
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
+
+func TestRunChecksDisabledNotifiers(t *testing.T) {
+	path := writeConfig(t, `
+domains:
+  - host: example.com
+notifications:
+  - name: pager
+    type: bogus
+    enabled: false
+  - name: hooks
+    type: webhook
+    enabled: false
+    webhok_url: https://hooks.example.com
+`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, want 1", code)
+	}
+	for _, want := range []string{
+		`notifier "pager" has unknown type "bogus"`,
+		`config.yaml:11: unknown field "webhok_url"`,
+		"has 2 problem(s)",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr does not mention %q:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestRunMissingFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d, want 1", code)
+	}
+}
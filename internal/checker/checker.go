@@ -1,10 +1,15 @@
 package checker
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/config"
@@ -18,21 +23,17 @@ func NewChecker() *Checker {
 	return &Checker{}
 }
 
-// CheckDomain performs a TLS handshake and extracts certificate expiry
-func (c *Checker) CheckDomain(domain config.DomainConfig) config.CheckResult {
+// CheckDomain performs a TLS handshake and extracts certificate expiry.
+// Network errors are retried as configured in domain.Connection.
+func (c *Checker) CheckDomain(ctx context.Context, domain config.DomainConfig) config.CheckResult {
 	result := config.CheckResult{
 		Domain: domain,
 	}
 
-	address := fmt.Sprintf("%s:%d", domain.Host, domain.Port)
-	conn, err := tls.DialWithDialer(
-		&net.Dialer{Timeout: 10 * time.Second},
-		"tcp",
-		address,
-		&tls.Config{
-			InsecureSkipVerify: domain.InsecureSkipVerify,
-		},
-	)
+	conn, attempts, err := c.dial(ctx, domain, &tls.Config{
+		InsecureSkipVerify: domain.InsecureSkipVerify,
+	})
+	result.Attempts = attempts
 	if err != nil {
 		result.Success = false
 		result.Error = fmt.Errorf("TLS handshake failed: %w", err)
@@ -52,22 +53,127 @@ func (c *Checker) CheckDomain(domain config.DomainConfig) config.CheckResult {
 	cert := certs[0]
 	result.Expiry = cert.NotAfter
 	result.DaysRemaining = time.Until(cert.NotAfter).Hours() / 24
+	result.Certificate = certificateInfo(cert, certs)
 	result.Success = true
 
 	return result
 }
 
+// dial connects to a domain and completes the TLS handshake. Network errors
+// are retried with exponential backoff; the number of attempts made is
+// returned with the connection.
+func (c *Checker) dial(ctx context.Context, domain config.DomainConfig, tlsConfig *tls.Config) (*tls.Conn, int, error) {
+	settings := domain.Connection
+	backoff := time.Duration(settings.RetryBackoff) * time.Second
+
+	for attempt := 1; ; attempt++ {
+		conn, err := c.handshake(ctx, domain, tlsConfig)
+		if err == nil || attempt > settings.Retries || !transient(err) {
+			return conn, attempt, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, attempt, err
+		}
+		backoff *= 2
+	}
+}
+
+// handshake makes a single connection attempt
+func (c *Checker) handshake(ctx context.Context, domain config.DomainConfig, tlsConfig *tls.Config) (*tls.Conn, error) {
+	settings := domain.Connection
+	dialer := &net.Dialer{Timeout: seconds(settings.DialTimeout, 10)}
+	if settings.SourceAddress != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(settings.SourceAddress)}
+	}
+
+	address := net.JoinHostPort(domain.Host, strconv.Itoa(domain.Port))
+	proxy, err := proxyFor(settings.Proxy, address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	var raw net.Conn
+	if proxy != nil {
+		dialCtx, cancel := context.WithTimeout(ctx, dialer.Timeout)
+		raw, err = dialProxy(dialCtx, dialer, proxy, address)
+		cancel()
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = domain.Host
+	conn := tls.Client(raw, tlsConfig)
+
+	ctx, cancel := context.WithTimeout(ctx, seconds(settings.HandshakeTimeout, 10))
+	defer cancel()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		raw.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// transient reports whether a connection error is worth retrying. Errors
+// about the certificate itself are not.
+func transient(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// seconds converts a setting in seconds, using def when it is unset
+func seconds(n, def int) time.Duration {
+	if n <= 0 {
+		n = def
+	}
+	return time.Duration(n) * time.Second
+}
+
+// certificateInfo summarizes a certificate and the chain it was found in
+func certificateInfo(leaf *x509.Certificate, certs []*x509.Certificate) config.CertificateInfo {
+	info := config.CertificateInfo{
+		Subject:      leaf.Subject.String(),
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: leaf.SerialNumber.Text(16),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+		DNSNames:     leaf.DNSNames,
+	}
+	for _, cert := range certs {
+		info.Chain = append(info.Chain, cert.Subject.String())
+	}
+	return info
+}
+
 // VerifyCertificateChain attempts to verify the certificate chain
-func (c *Checker) VerifyCertificateChain(domain config.DomainConfig) error {
-	address := fmt.Sprintf("%s:%d", domain.Host, domain.Port)
-	conn, err := tls.DialWithDialer(
-		&net.Dialer{Timeout: 10 * time.Second},
-		"tcp",
-		address,
-		&tls.Config{
-			InsecureSkipVerify: false,
-		},
-	)
+func (c *Checker) VerifyCertificateChain(ctx context.Context, domain config.DomainConfig) error {
+	if domain.File != "" {
+		return c.verifyFiles(domain)
+	}
+	conn, _, err := c.dial(ctx, domain, &tls.Config{
+		InsecureSkipVerify: false,
+	})
 	if err != nil {
 		return fmt.Errorf("TLS handshake failed: %w", err)
 	}
@@ -102,4 +208,4 @@ func (c *Checker) VerifyCertificateChain(domain config.DomainConfig) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,128 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// closedPort returns a local port nothing is listening on
+func closedPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+// tlsTarget returns the port of a local TLS server
+func tlsTarget(t *testing.T) int {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+func TestCheckDomain(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	domain := config.DomainConfig{Host: "127.0.0.1", Port: port, InsecureSkipVerify: true}
+	r := NewChecker().CheckDomain(context.Background(), domain)
+	if !r.Success {
+		t.Fatalf("check failed: %v", r.Error)
+	}
+	leaf := srv.Certificate()
+	if !r.Expiry.Equal(leaf.NotAfter) {
+		t.Errorf("expiry = %v, want %v", r.Expiry, leaf.NotAfter)
+	}
+	if r.DaysRemaining <= 0 {
+		t.Errorf("days remaining = %v", r.DaysRemaining)
+	}
+	if r.Certificate.SerialNumber != leaf.SerialNumber.Text(16) {
+		t.Errorf("serial = %q", r.Certificate.SerialNumber)
+	}
+	if r.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", r.Attempts)
+	}
+}
+
+func TestCheckDomainRetries(t *testing.T) {
+	domain := config.DomainConfig{
+		Host:       "127.0.0.1",
+		Port:       closedPort(t),
+		Connection: config.ConnectionConfig{Retries: 2},
+	}
+	r := NewChecker().CheckDomain(context.Background(), domain)
+	if r.Success {
+		t.Fatal("check of a closed port succeeded")
+	}
+	if r.Attempts != 3 {
+		t.Errorf("attempts = %d, want 3", r.Attempts)
+	}
+	if !strings.Contains(r.Error.Error(), "connection refused") {
+		t.Errorf("error = %v", r.Error)
+	}
+}
+
+func TestCheckDomainVerificationNotRetried(t *testing.T) {
+	domain := config.DomainConfig{
+		Host:       "127.0.0.1",
+		Port:       tlsTarget(t),
+		Connection: config.ConnectionConfig{Retries: 3},
+	}
+	r := NewChecker().CheckDomain(context.Background(), domain)
+	if r.Success {
+		t.Fatal("untrusted certificate was accepted")
+	}
+	if r.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", r.Attempts)
+	}
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(r.Error, &certErr) {
+		t.Errorf("error = %v, want a verification error", r.Error)
+	}
+}
+
+func TestCheckDomainSourceAddress(t *testing.T) {
+	remotes := make(chan string, 1)
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			remotes <- host
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	domain := config.DomainConfig{
+		Host:               "127.0.0.1",
+		Port:               port,
+		InsecureSkipVerify: true,
+		Connection:         config.ConnectionConfig{SourceAddress: "127.0.0.2"},
+	}
+	if r := NewChecker().CheckDomain(context.Background(), domain); !r.Success {
+		t.Fatalf("check failed: %v", r.Error)
+	}
+	if remote := <-remotes; remote != "127.0.0.2" {
+		t.Errorf("connected from %s, want 127.0.0.2", remote)
+	}
+}
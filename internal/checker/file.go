@@ -0,0 +1,257 @@
+package checker
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// certificateExtensions are the files read from a directory
+var certificateExtensions = map[string]bool{
+	".pem":      true,
+	".crt":      true,
+	".cer":      true,
+	".cert":     true,
+	".der":      true,
+	".p12":      true,
+	".pfx":      true,
+	".jks":      true,
+	".jceks":    true,
+	".keystore": true,
+}
+
+// certificateEntry is a certificate with its chain. Entries of keystores
+// have an alias.
+type certificateEntry struct {
+	alias    string
+	certs    []*x509.Certificate
+	warnings []string
+}
+
+// CheckFiles reads the certificates of a domain whose File is set instead
+// of a host. File may be a glob pattern or a directory, and keystores hold
+// several entries, so there is a result per certificate file or keystore
+// entry, each for a copy of domain with File and Alias set accordingly.
+func (c *Checker) CheckFiles(domain config.DomainConfig) []config.CheckResult {
+	paths, err := certificateFiles(domain.File)
+	if err != nil {
+		return []config.CheckResult{{Domain: domain, Error: err}}
+	}
+
+	expanded := len(paths) != 1 || paths[0] != domain.File
+	var results []config.CheckResult
+	for _, path := range paths {
+		d := domain
+		d.File = path
+		entries, err := readCertificates(path, domain.Password)
+		if err != nil {
+			d.Name = entryName(domain.Name, path, "", expanded)
+			results = append(results, config.CheckResult{Domain: d, Error: err})
+			continue
+		}
+		for _, entry := range entries {
+			d.Alias = entry.alias
+			d.Name = entryName(domain.Name, path, entry.alias, expanded)
+			result := entryResult(d, entry.certs)
+			result.Warnings = entry.warnings
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// entryName tells the certificates of a named domain apart by file name
+// and keystore alias
+func entryName(name, path, alias string, expanded bool) string {
+	var parts []string
+	if expanded {
+		parts = append(parts, filepath.Base(path))
+	}
+	if alias != "" {
+		parts = append(parts, alias)
+	}
+	if name == "" || len(parts) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(parts, ", "))
+}
+
+// entryResult reports a certificate and its chain. An entry holding
+// several certificates, such as a chain, is reported by the one that
+// expires first.
+func entryResult(domain config.DomainConfig, certs []*x509.Certificate) config.CheckResult {
+	result := config.CheckResult{
+		Domain: domain,
+	}
+
+	expiring := certs[0]
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(expiring.NotAfter) {
+			expiring = cert
+		}
+	}
+	result.Expiry = expiring.NotAfter
+	result.DaysRemaining = time.Until(expiring.NotAfter).Hours() / 24
+	result.Certificate = certificateInfo(expiring, certs)
+	result.Success = true
+	return result
+}
+
+// certificateFiles expands a file setting into the files to read. Matching
+// directories contribute their files with a certificate extension.
+func certificateFiles(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		if strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("no certificate files match %s", pattern)
+		}
+		return nil, fmt.Errorf("certificate file %s does not exist", pattern)
+	}
+
+	var paths []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, match)
+			continue
+		}
+
+		entries, err := os.ReadDir(match)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || !certificateExtensions[strings.ToLower(filepath.Ext(name))] {
+				continue
+			}
+			// Follow symlinks, as in /etc/ssl/certs
+			path := filepath.Join(match, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no certificate files found in %s", pattern)
+	}
+	return paths, nil
+}
+
+// readCertificates reads the certificates of a PEM, DER, PKCS#12 or Java
+// keystore file. The format is recognized by the extension and the
+// contents. Only keystores have more than one entry.
+func readCertificates(path, password string) ([]certificateEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isKeystore(data) {
+		entries, err := decodeKeystore(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return entries, nil
+	}
+	certs, err := parseCertificates(data, filepath.Ext(path), password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return []certificateEntry{{certs: certs}}, nil
+}
+
+// parseCertificates decodes the certificates of a file with extension ext
+func parseCertificates(data []byte, ext, password string) ([]*x509.Certificate, error) {
+	switch ext = strings.ToLower(ext); {
+	case ext == ".p12" || ext == ".pfx":
+		return decodePKCS12(data, password)
+	case bytes.Contains(data, []byte("-----BEGIN")):
+		return parsePEM(data)
+	}
+
+	// DER, possibly several certificates in a row
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		return certs, nil
+	}
+	certs, err := decodePKCS12(data, password)
+	if errors.Is(err, errNotPKCS12) {
+		return nil, errors.New("unrecognized certificate format, expected PEM, DER, PKCS#12, JKS or JCEKS")
+	}
+	return certs, err
+}
+
+// parsePEM decodes the CERTIFICATE blocks of a PEM file. Other blocks, such
+// as private keys, are skipped.
+func parsePEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// verifyFiles verifies the chain of each certificate file or keystore entry
+// of a domain against the system roots. The first certificate is taken as
+// the leaf and the others as intermediates.
+func (c *Checker) verifyFiles(domain config.DomainConfig) error {
+	paths, err := certificateFiles(domain.File)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, path := range paths {
+		entries, err := readCertificates(path, domain.Password)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, entry := range entries {
+			intermediates := x509.NewCertPool()
+			for _, cert := range entry.certs[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err = entry.certs[0].Verify(x509.VerifyOptions{
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			})
+			if err != nil {
+				where := path
+				if entry.alias != "" {
+					where += "#" + entry.alias
+				}
+				errs = append(errs, fmt.Errorf("%s: certificate verification failed: %w", where, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// The fixtures in testdata are written by OpenSSL, see testdata/gen.sh.
+// Archives are protected with the password s3cret.
+
+func TestCheckFilesFormats(t *testing.T) {
+	tests := []struct {
+		file     string
+		password string
+		chain    int // certificates found
+	}{
+		{"leaf.pem", "", 1},
+		{"chain.pem", "", 2},
+		{"key-and-cert.pem", "", 1},
+		{"leaf.der", "", 1},
+		{"chain.der", "", 2},
+		{"modern.p12", "s3cret", 2},
+		{"legacy.p12", "s3cret", 2},
+		{"3des.p12", "s3cret", 2},
+		{"nomac.p12", "s3cret", 1},
+		{"unencrypted.p12", "s3cret", 1},
+		{"nopass.pfx", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			results := NewChecker().CheckFiles(config.DomainConfig{File: filepath.Join("testdata", tt.file), Password: tt.password})
+			if len(results) != 1 {
+				t.Fatalf("got %d results", len(results))
+			}
+			r := results[0]
+			if !r.Success {
+				t.Fatalf("check failed: %v", r.Error)
+			}
+			if r.Certificate.Subject != "CN=leaf.example.com" {
+				t.Errorf("subject = %q", r.Certificate.Subject)
+			}
+			if len(r.Certificate.Chain) != tt.chain {
+				t.Errorf("chain = %q, want %d certificates", r.Certificate.Chain, tt.chain)
+			}
+			if r.DaysRemaining < 365*99 {
+				t.Errorf("days remaining = %.1f", r.DaysRemaining)
+			}
+		})
+	}
+}
+
+func TestCheckFilesErrors(t *testing.T) {
+	tests := []struct {
+		file     string
+		password string
+		want     string
+	}{
+		{"modern.p12", "wrong", "wrong PKCS#12 password"},
+		{"legacy.p12", "", "wrong PKCS#12 password"},
+		{"3des.p12", "wrong", "wrong PKCS#12 password"},
+		{"nopass.pfx", "s3cret", "wrong PKCS#12 password"},
+		{"missing.pem", "", "certificate file testdata/missing.pem does not exist"},
+		{"*.missing", "", "no certificate files match testdata/*.missing"},
+		{"gen.sh", "", "unrecognized certificate format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.password, func(t *testing.T) {
+			results := NewChecker().CheckFiles(config.DomainConfig{File: filepath.Join("testdata", tt.file), Password: tt.password})
+			if len(results) != 1 || results[0].Success {
+				t.Fatalf("results = %+v", results)
+			}
+			if err := results[0].Error; err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Cutting a file short must give an error, never a panic or a partial
+// result
+func TestCheckFilesTruncated(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"leaf.pem", "chain.der", "modern.p12", "legacy.p12", "nomac.p12"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int{0, 1, 4, len(data) / 3, len(data) / 2, len(data) - 10} {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, data[:size], 0o600); err != nil {
+				t.Fatal(err)
+			}
+			results := NewChecker().CheckFiles(config.DomainConfig{File: path, Password: "s3cret"})
+			if len(results) != 1 || results[0].Error == nil {
+				t.Errorf("%s cut to %d bytes: results = %+v", name, size, results)
+			}
+		}
+	}
+}
+
+func TestCheckFilesDirectory(t *testing.T) {
+	results := NewChecker().CheckFiles(config.DomainConfig{File: "testdata", Name: "certs", Password: "s3cret"})
+	names := make(map[string]bool)
+	for _, r := range results {
+		names[r.Domain.Name] = true
+		if r.Domain.File == "testdata" {
+			t.Errorf("result without its own file: %+v", r.Domain)
+		}
+	}
+	// gen.sh has no certificate extension and is skipped
+	for _, want := range []string{"certs (leaf.pem)", "certs (modern.p12)", "certs (chain.der)"} {
+		if !names[want] {
+			t.Errorf("missing %q in %v", want, names)
+		}
+	}
+	if names["certs (gen.sh)"] {
+		t.Error("gen.sh was read")
+	}
+}
+
+func TestVerifyFiles(t *testing.T) {
+	// The test CA is not a system root
+	err := NewChecker().verifyFiles(config.DomainConfig{File: filepath.Join("testdata", "chain.pem")})
+	if err == nil || !strings.Contains(err.Error(), "certificate verification failed") {
+		t.Errorf("error = %v", err)
+	}
+}
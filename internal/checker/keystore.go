@@ -0,0 +1,434 @@
+package checker
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Java keystores (JKS and JCEKS) are read for the certificates of their
+// entries. Keys are skipped without being decrypted, so the store password
+// only serves to check the integrity of the file.
+
+const (
+	jksMagic   = 0xfeedfeed
+	jceksMagic = 0xcececece
+
+	keystorePrivateKey  = 1
+	keystoreTrustedCert = 2
+	keystoreSecretKey   = 3 // JCEKS only
+)
+
+var errKeystorePassword = errors.New("wrong keystore password or corrupted file")
+
+// errKeystoreTruncated is returned when an entry runs past the end of the
+// file
+var errKeystoreTruncated = errors.New("truncated keystore")
+
+// isKeystore reports whether data starts with a JKS or JCEKS header
+func isKeystore(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	magic := binary.BigEndian.Uint32(data)
+	return magic == jksMagic || magic == jceksMagic
+}
+
+// decodeKeystore returns an entry per alias of a JKS or JCEKS keystore
+// holding certificates: trusted certificates, and the chains of private
+// keys. The integrity of the store is verified when a password is given,
+// otherwise the entries carry a warning.
+func decodeKeystore(data []byte, password string) ([]certificateEntry, error) {
+	if len(data) < 12+sha1.Size {
+		return nil, errKeystoreTruncated
+	}
+	body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	var warnings []string
+	if password == "" {
+		warnings = append(warnings, "keystore integrity not verified, set password to check it")
+	} else if subtle.ConstantTimeCompare(keystoreDigest(body, password), digest) != 1 {
+		return nil, errKeystorePassword
+	}
+
+	r := &keystoreReader{data: body}
+	magic := r.uint32()
+	version := r.uint32()
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported keystore version %d", version)
+	}
+	count := r.uint32()
+
+	var entries []certificateEntry
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		tag := r.uint32()
+		alias := r.utf()
+		r.skip(8) // creation date
+		entry := certificateEntry{alias: alias, warnings: warnings}
+		switch tag {
+		case keystorePrivateKey:
+			r.skip(int(r.uint32()))
+			n := r.uint32()
+			for j := uint32(0); j < n && r.err == nil; j++ {
+				entry.certs = append(entry.certs, r.certificate(version))
+			}
+		case keystoreTrustedCert:
+			entry.certs = append(entry.certs, r.certificate(version))
+		case keystoreSecretKey:
+			if magic != jceksMagic {
+				return nil, fmt.Errorf("unknown keystore entry type %d", tag)
+			}
+			// A serialized javax.crypto.SealedObject, without certificates
+			r.sealedObject()
+		default:
+			return nil, fmt.Errorf("unknown keystore entry type %d", tag)
+		}
+		if r.err == nil && len(entry.certs) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("invalid keystore: unexpected data after the entries")
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no certificates found in keystore")
+	}
+	return entries, nil
+}
+
+// keystoreDigest computes the keyed SHA-1 hash that ends a keystore. The
+// key is the password as UTF-16 followed by a phrase from the JDK.
+func keystoreDigest(body []byte, password string) []byte {
+	h := sha1.New()
+	for _, c := range utf16.Encode([]rune(password)) {
+		h.Write([]byte{byte(c >> 8), byte(c)})
+	}
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// keystoreReader reads the big-endian fields of a keystore as written by
+// java.io.DataOutputStream. After the first error, reads return zero values
+// and err holds the error.
+type keystoreReader struct {
+	data []byte
+	err  error
+
+	// handles of the Java serialization stream being read
+	handles []interface{}
+}
+
+func (r *keystoreReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errKeystoreTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *keystoreReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *keystoreReader) uint8() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *keystoreReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *keystoreReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *keystoreReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// utf reads a string written by DataOutputStream.writeUTF
+func (r *keystoreReader) utf() string {
+	return decodeModifiedUTF8(r.bytes(int(r.uint16())))
+}
+
+// certificate reads a certificate of a keystore entry. Version 2 stores
+// the certificate type before each certificate.
+func (r *keystoreReader) certificate(version uint32) *x509.Certificate {
+	if version == 2 {
+		if typ := r.utf(); typ != "X.509" && r.err == nil {
+			r.err = fmt.Errorf("unsupported certificate type %q in keystore", typ)
+		}
+	}
+	der := r.bytes(int(r.uint32()))
+	if r.err != nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		r.err = fmt.Errorf("invalid certificate in keystore: %w", err)
+	}
+	return cert
+}
+
+// decodeModifiedUTF8 decodes Java's modified UTF-8, which encodes NUL in
+// two bytes and characters outside the BMP as surrogate pairs
+func decodeModifiedUTF8(b []byte) string {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1f)<<6|uint16(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0f)<<12|uint16(b[i+1]&0x3f)<<6|uint16(b[i+2]&0x3f))
+			i += 3
+		default:
+			units = append(units, 0xfffd)
+			i++
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// Java object serialization, of which only as much is implemented as it
+// takes to skip the sealed secret keys of JCEKS keystores
+
+const (
+	tcNull           = 0x70
+	tcReference      = 0x71
+	tcClassDesc      = 0x72
+	tcObject         = 0x73
+	tcString         = 0x74
+	tcArray          = 0x75
+	tcClass          = 0x76
+	tcBlockData      = 0x77
+	tcEndBlockData   = 0x78
+	tcReset          = 0x79
+	tcBlockDataLong  = 0x7a
+	tcLongString     = 0x7c
+	tcProxyClassDesc = 0x7d
+	tcEnum           = 0x7e
+
+	scWriteMethod    = 0x01
+	scSerializable   = 0x02
+	scExternalizable = 0x04
+	scBlockData      = 0x08
+
+	javaStreamMagic = 0xaced
+	javaBaseHandle  = 0x7e0000
+)
+
+// javaClass is a class descriptor of a serialization stream
+type javaClass struct {
+	name   string
+	flags  byte
+	fields []byte // type codes of the fields
+	super  *javaClass
+}
+
+var errJavaSerialization = errors.New("invalid serialized object in keystore")
+
+// sealedObject skips a serialized object, written with a stream of its own
+func (r *keystoreReader) sealedObject() {
+	if r.uint16() != javaStreamMagic || r.uint16() != 5 {
+		r.fail()
+		return
+	}
+	r.handles = r.handles[:0]
+	r.javaContent()
+}
+
+func (r *keystoreReader) fail() {
+	if r.err == nil {
+		r.err = errJavaSerialization
+	}
+}
+
+// newHandle assigns the next handle to v
+func (r *keystoreReader) newHandle(v interface{}) {
+	r.handles = append(r.handles, v)
+}
+
+// javaContent skips an object or block of data and returns the class
+// descriptor if that is what was read
+func (r *keystoreReader) javaContent() *javaClass {
+	if r.err != nil {
+		return nil
+	}
+	switch tc := r.uint8(); tc {
+	case tcNull:
+	case tcReference:
+		h := int(r.uint32()) - javaBaseHandle
+		if h < 0 || h >= len(r.handles) {
+			r.fail()
+			return nil
+		}
+		class, _ := r.handles[h].(*javaClass)
+		return class
+	case tcClassDesc, tcProxyClassDesc:
+		return r.javaClassDesc(tc)
+	case tcObject:
+		class := r.javaClassRef()
+		r.newHandle(nil)
+		r.javaClassData(class)
+	case tcString:
+		r.newHandle(nil)
+		r.skip(int(r.uint16()))
+	case tcLongString:
+		r.newHandle(nil)
+		r.skip(int(r.uint64()))
+	case tcArray:
+		class := r.javaClassRef()
+		r.newHandle(nil)
+		n := int(r.uint32())
+		if class == nil || len(class.name) < 2 || class.name[0] != '[' {
+			r.fail()
+			return nil
+		}
+		for i := 0; i < n && r.err == nil; i++ {
+			r.javaValue(class.name[1])
+		}
+	case tcClass:
+		r.javaClassRef()
+		r.newHandle(nil)
+	case tcEnum:
+		r.javaClassRef()
+		r.newHandle(nil)
+		r.javaContent()
+	case tcBlockData:
+		r.skip(int(r.uint8()))
+	case tcBlockDataLong:
+		r.skip(int(r.uint32()))
+	case tcReset:
+		r.handles = r.handles[:0]
+		return r.javaContent()
+	default:
+		r.fail()
+	}
+	return nil
+}
+
+// javaClassRef reads the class descriptor of an object: a new descriptor,
+// a reference to one, or null
+func (r *keystoreReader) javaClassRef() *javaClass {
+	if r.err != nil || len(r.data) == 0 {
+		r.bytes(1)
+		return nil
+	}
+	switch r.data[0] {
+	case tcClassDesc, tcProxyClassDesc, tcReference, tcNull:
+		return r.javaContent()
+	}
+	r.fail()
+	return nil
+}
+
+// javaClassDesc reads a class descriptor after its type code
+func (r *keystoreReader) javaClassDesc(tc byte) *javaClass {
+	class := &javaClass{}
+	if tc == tcProxyClassDesc {
+		r.newHandle(class)
+		class.flags = scSerializable
+		for n := r.uint32(); n > 0 && r.err == nil; n-- {
+			r.utf()
+		}
+	} else {
+		class.name = r.utf()
+		r.skip(8) // serialVersionUID
+		r.newHandle(class)
+		class.flags = r.uint8()
+		for n := r.uint16(); n > 0 && r.err == nil; n-- {
+			code := r.uint8()
+			r.utf()
+			if code == '[' || code == 'L' {
+				r.javaContent() // class name of the field
+			}
+			class.fields = append(class.fields, code)
+		}
+	}
+	r.javaAnnotation()
+	class.super = r.javaClassRef()
+	return class
+}
+
+// javaAnnotation skips contents up to the end of a block
+func (r *keystoreReader) javaAnnotation() {
+	for r.err == nil {
+		if len(r.data) > 0 && r.data[0] == tcEndBlockData {
+			r.skip(1)
+			return
+		}
+		r.javaContent()
+	}
+}
+
+// javaClassData skips the fields of an object, from its topmost class down
+func (r *keystoreReader) javaClassData(class *javaClass) {
+	var hierarchy []*javaClass
+	for c := class; c != nil; c = c.super {
+		hierarchy = append(hierarchy, c)
+	}
+	for i := len(hierarchy) - 1; i >= 0 && r.err == nil; i-- {
+		c := hierarchy[i]
+		switch {
+		case c.flags&scExternalizable != 0:
+			if c.flags&scBlockData == 0 {
+				r.fail()
+				return
+			}
+			r.javaAnnotation()
+		case c.flags&scSerializable != 0:
+			for _, code := range c.fields {
+				r.javaValue(code)
+			}
+			if c.flags&scWriteMethod != 0 {
+				r.javaAnnotation()
+			}
+		}
+	}
+}
+
+// javaValue skips a field or array element of the given type code
+func (r *keystoreReader) javaValue(code byte) {
+	switch code {
+	case 'B', 'Z':
+		r.skip(1)
+	case 'C', 'S':
+		r.skip(2)
+	case 'I', 'F':
+		r.skip(4)
+	case 'J', 'D':
+		r.skip(8)
+	case 'L', '[':
+		r.javaContent()
+	default:
+		r.fail()
+	}
+}
@@ -0,0 +1,283 @@
+package checker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// No JDK is needed to run the tests: the keystores are assembled here,
+// field by field, in the layout keytool writes (JavaKeyStore and
+// JceKeyStore in the JDK sources), from the certificates in testdata.
+
+// keystoreWriter writes big-endian fields like java.io.DataOutputStream
+type keystoreWriter struct {
+	bytes.Buffer
+}
+
+func (w *keystoreWriter) u8(v byte)    { w.WriteByte(v) }
+func (w *keystoreWriter) u16(v uint16) { binary.Write(w, binary.BigEndian, v) }
+func (w *keystoreWriter) u32(v uint32) { binary.Write(w, binary.BigEndian, v) }
+func (w *keystoreWriter) u64(v uint64) { binary.Write(w, binary.BigEndian, v) }
+
+func (w *keystoreWriter) utf(s string) {
+	w.u16(uint16(len(s)))
+	w.WriteString(s)
+}
+
+func (w *keystoreWriter) certificate(version uint32, der []byte) {
+	if version == 2 {
+		w.utf("X.509")
+	}
+	w.u32(uint32(len(der)))
+	w.Write(der)
+}
+
+// sealedKey writes a secret key entry's value as the JDK serializes it: a
+// com.sun.crypto.provider.SealedObjectForKeyProtector, whose superclass
+// javax.crypto.SealedObject holds byte arrays and strings
+func (w *keystoreWriter) sealedKey() {
+	w.u16(javaStreamMagic)
+	w.u16(5)
+	w.u8(tcObject)
+	w.u8(tcClassDesc)
+	w.utf("com.sun.crypto.provider.SealedObjectForKeyProtector")
+	w.u64(0x4d57ca59e7300ebb)
+	w.u8(scSerializable)
+	w.u16(0) // no fields
+	w.u8(tcEndBlockData)
+
+	w.u8(tcClassDesc) // superclass, handle 1
+	w.utf("javax.crypto.SealedObject")
+	w.u64(0x3e363da6c3b75470)
+	w.u8(scSerializable)
+	w.u16(4)
+	w.u8('[')
+	w.utf("encodedParams")
+	w.u8(tcString) // handle 2
+	w.utf("[B")
+	w.u8('[')
+	w.utf("encryptedContent")
+	w.u8(tcReference)
+	w.u32(javaBaseHandle + 2)
+	w.u8('L')
+	w.utf("paramsAlg")
+	w.u8(tcString) // handle 3
+	w.utf("Ljava/lang/String;")
+	w.u8('L')
+	w.utf("sealAlg")
+	w.u8(tcReference)
+	w.u32(javaBaseHandle + 3)
+	w.u8(tcEndBlockData)
+	w.u8(tcNull) // no further superclass
+
+	// The object is handle 4, then the fields of SealedObject
+	w.u8(tcArray)
+	w.u8(tcClassDesc) // handle 5
+	w.utf("[B")
+	w.u64(0xacf317f8060854e0)
+	w.u8(scSerializable)
+	w.u16(0)
+	w.u8(tcEndBlockData)
+	w.u8(tcNull)
+	w.u32(15) // handle 6
+	w.Write(make([]byte, 15))
+	w.u8(tcArray)
+	w.u8(tcReference)
+	w.u32(javaBaseHandle + 5)
+	w.u32(32) // handle 7
+	w.Write(make([]byte, 32))
+	w.u8(tcString) // handle 8
+	w.utf("PBEWithMD5AndTripleDES")
+	w.u8(tcReference)
+	w.u32(javaBaseHandle + 8)
+}
+
+// readDER returns the first certificate of a PEM fixture
+func readDER(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	return block.Bytes
+}
+
+// buildKeystore returns a keystore with a private key entry "tomcat"
+// holding the leaf and CA certificates, a trusted certificate entry
+// "root-caé" and, with secret set, a secret key entry in between
+func buildKeystore(t testing.TB, magic, version uint32, password string, secret bool) []byte {
+	t.Helper()
+	leaf, ca := readDER(t, "leaf.pem"), readDER(t, "ca.pem")
+
+	var w keystoreWriter
+	w.u32(magic)
+	w.u32(version)
+	if secret {
+		w.u32(3)
+	} else {
+		w.u32(2)
+	}
+
+	w.u32(keystorePrivateKey)
+	w.utf("tomcat")
+	w.u64(1700000000000)
+	w.u32(5)
+	w.WriteString("key!!") // never decrypted
+	w.u32(2)
+	w.certificate(version, leaf)
+	w.certificate(version, ca)
+
+	if secret {
+		w.u32(keystoreSecretKey)
+		w.utf("aes-key")
+		w.u64(1700000000000)
+		w.sealedKey()
+	}
+
+	w.u32(keystoreTrustedCert)
+	w.utf("root-caé")
+	w.u64(1700000000000)
+	w.certificate(version, ca)
+
+	w.Write(keystoreDigest(w.Bytes(), password))
+	return w.Bytes()
+}
+
+func TestDecodeKeystore(t *testing.T) {
+	tests := []struct {
+		name    string
+		magic   uint32
+		version uint32
+		secret  bool
+	}{
+		{"JKS v1", jksMagic, 1, false},
+		{"JKS v2", jksMagic, 2, false},
+		{"JCEKS", jceksMagic, 2, false},
+		{"JCEKS with a secret key", jceksMagic, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildKeystore(t, tt.magic, tt.version, "changeit", tt.secret)
+			if !isKeystore(data) {
+				t.Fatal("not recognized as a keystore")
+			}
+			entries, err := decodeKeystore(data, "changeit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			if e := entries[0]; e.alias != "tomcat" || len(e.certs) != 2 || e.certs[0].Subject.CommonName != "leaf.example.com" {
+				t.Errorf("first entry = %s with %d certificates", e.alias, len(e.certs))
+			}
+			if e := entries[1]; e.alias != "root-caé" || len(e.certs) != 1 || e.certs[0].Subject.CommonName != "Test CA" {
+				t.Errorf("second entry = %s with %d certificates", e.alias, len(e.certs))
+			}
+			if len(entries[0].warnings) != 0 {
+				t.Errorf("warnings = %q", entries[0].warnings)
+			}
+		})
+	}
+}
+
+func TestDecodeKeystorePassword(t *testing.T) {
+	data := buildKeystore(t, jksMagic, 2, "changeit", false)
+
+	if _, err := decodeKeystore(data, "wrong"); !errors.Is(err, errKeystorePassword) {
+		t.Errorf("wrong password: error = %v", err)
+	}
+
+	// Without a password the entries are read but not verified
+	entries, err := decodeKeystore(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if len(e.warnings) != 1 || !strings.Contains(e.warnings[0], "integrity not verified") {
+			t.Errorf("%s: warnings = %q", e.alias, e.warnings)
+		}
+	}
+}
+
+func TestDecodeKeystoreErrors(t *testing.T) {
+	if _, err := decodeKeystore(buildKeystore(t, jksMagic, 2, "", true), ""); err == nil || !strings.Contains(err.Error(), "unknown keystore entry type 3") {
+		t.Errorf("secret key in JKS: error = %v", err)
+	}
+
+	data := buildKeystore(t, jceksMagic, 2, "changeit", true)
+	for size := 0; size < len(data); size += 7 {
+		if _, err := decodeKeystore(data[:size], ""); err == nil {
+			t.Errorf("keystore cut to %d bytes was accepted", size)
+		}
+	}
+}
+
+func TestCheckFilesKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.jceks")
+	if err := os.WriteFile(path, buildKeystore(t, jceksMagic, 2, "changeit", true), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	results := NewChecker().CheckFiles(config.DomainConfig{File: path, Name: "tomcat", Password: "changeit"})
+	if len(results) != 2 {
+		t.Fatalf("got %d results", len(results))
+	}
+	for i, want := range []struct{ key, name string }{
+		{path + "#tomcat", "tomcat (tomcat)"},
+		{path + "#root-caé", "tomcat (root-caé)"},
+	} {
+		r := results[i]
+		if !r.Success || r.Domain.Key() != want.key || r.Domain.Name != want.name {
+			t.Errorf("result %d: key %q, name %q, error %v", i, r.Domain.Key(), r.Domain.Name, r.Error)
+		}
+	}
+
+	results = NewChecker().CheckFiles(config.DomainConfig{File: path, Password: "wrong"})
+	if len(results) != 1 || results[0].Error == nil || !strings.Contains(results[0].Error.Error(), "wrong keystore password") {
+		t.Errorf("wrong password: results = %+v", results)
+	}
+
+	results = NewChecker().CheckFiles(config.DomainConfig{File: path})
+	if len(results) != 2 || len(results[0].Warnings) != 1 {
+		t.Errorf("no password: results = %+v", results)
+	}
+}
+
+func TestDecodeModifiedUTF8(t *testing.T) {
+	tests := map[string]string{
+		"alias":                    "alias",
+		"a\xc0\x80b":               "a\x00b",
+		"caf\xc3\xa9":              "café",
+		"\xed\xa0\xbd\xed\xb8\x80": "\U0001f600", // surrogate pair
+		"\xff":                     "�",
+	}
+	for in, want := range tests {
+		if got := decodeModifiedUTF8([]byte(in)); got != want {
+			t.Errorf("decodeModifiedUTF8(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func FuzzDecodeKeystore(f *testing.F) {
+	for _, magic := range []uint32{jksMagic, jceksMagic} {
+		for _, version := range []uint32{1, 2} {
+			f.Add(buildKeystore(f, magic, version, "changeit", magic == jceksMagic), "")
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte, password string) {
+		entries, err := decodeKeystore(data, password)
+		if err == nil && len(entries) == 0 {
+			t.Error("no error and no entries")
+		}
+	})
+}
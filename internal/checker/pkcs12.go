@@ -0,0 +1,646 @@
+package checker
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+// PKCS#12 (RFC 7292) support is limited to reading the certificates of an
+// archive. Private keys are skipped without being decrypted.
+
+// maxIterations limits the work of key derivation, so a crafted file
+// cannot stall the checker. Tools use a few thousand iterations.
+const maxIterations = 1000000
+
+var (
+	errNotPKCS12      = errors.New("not a PKCS#12 file")
+	errPKCS12Password = errors.New("wrong PKCS#12 password or corrupted file")
+)
+
+var (
+	oidData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidCertBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidSafeContentsBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 6}
+	oidX509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidLocalKeyID      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd128BitRC2CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHAAnd40BitRC2CBC      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidDESEDE3CBC                    = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// digests are the hash functions of MACs and PBKDF2, by the OIDs of the
+// digest and of the HMAC algorithm
+var digests = []struct {
+	digest, hmac asn1.ObjectIdentifier
+	hash         func() hash.Hash
+}{
+	{asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}, asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}, sha1.New},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}, sha256.New},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}, asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}, sha512.New384},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}, asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}, sha512.New},
+}
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+// contentInfo is a PKCS#7 ContentInfo. Content is an [0] EXPLICIT
+// element, whose tag a RawValue keeps, see explicit.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"tag:0,optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       asn1.RawValue
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decodePKCS12 returns the certificates of a PKCS#12 archive, those with
+// a private key first. The password is checked against the archive's MAC.
+func decodePKCS12(data []byte, password string) ([]*x509.Certificate, error) {
+	var pfx pfxPDU
+	if err := unmarshalBER(data, &pfx); err != nil {
+		return nil, errNotPKCS12
+	}
+	if pfx.Version != 3 {
+		return nil, fmt.Errorf("unsupported PKCS#12 version %d", pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidData) {
+		return nil, errors.New("PKCS#12 files protected with public keys are not supported")
+	}
+	authSafe, err := explicitOctets(pfx.AuthSafe.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty password is encoded either as two zero bytes or as nothing
+	secret := bmpString(password)
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) > 0 {
+		err := verifyMAC(&pfx.MacData, authSafe, secret)
+		if errors.Is(err, errPKCS12Password) && password == "" {
+			secret = nil
+			err = verifyMAC(&pfx.MacData, authSafe, secret)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var contents []contentInfo
+	if err := unmarshalBER(authSafe, &contents); err != nil {
+		return nil, fmt.Errorf("invalid PKCS#12 contents: %w", err)
+	}
+	var withKey, others []*x509.Certificate
+	for _, ci := range contents {
+		var bags []byte
+		switch {
+		case ci.ContentType.Equal(oidData):
+			bags, err = explicitOctets(ci.Content)
+		case ci.ContentType.Equal(oidEncryptedData):
+			var content asn1.RawValue
+			if content, err = explicit(ci.Content); err == nil {
+				bags, err = decryptContent(content.FullBytes, secret, password)
+			}
+		default:
+			// Public-key encrypted contents cannot be read with a password
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := collectCertificates(bags, &withKey, &others); err != nil {
+			return nil, err
+		}
+	}
+
+	certs := append(withKey, others...)
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// collectCertificates parses the certificate bags of a SafeContents
+// structure. Certificates with a local key ID belong to a private key.
+func collectCertificates(data []byte, withKey, others *[]*x509.Certificate) error {
+	var bags []safeBag
+	if err := unmarshalBER(data, &bags); err != nil {
+		return fmt.Errorf("invalid PKCS#12 contents: %w", err)
+	}
+	for _, bag := range bags {
+		value, err := explicit(bag.Value)
+		if err != nil {
+			return fmt.Errorf("invalid PKCS#12 bag: %w", err)
+		}
+		switch {
+		case bag.ID.Equal(oidSafeContentsBag):
+			if err := collectCertificates(value.FullBytes, withKey, others); err != nil {
+				return err
+			}
+		case bag.ID.Equal(oidCertBag):
+			var cb certBag
+			if err := unmarshalBER(value.FullBytes, &cb); err != nil {
+				return fmt.Errorf("invalid PKCS#12 certificate bag: %w", err)
+			}
+			if !cb.ID.Equal(oidX509Certificate) {
+				continue
+			}
+			cert, err := x509.ParseCertificate(cb.Data)
+			if err != nil {
+				return fmt.Errorf("invalid certificate: %w", err)
+			}
+			if hasAttribute(bag.Attributes, oidLocalKeyID) {
+				*withKey = append(*withKey, cert)
+			} else {
+				*others = append(*others, cert)
+			}
+		}
+	}
+	return nil
+}
+
+// hasAttribute reports whether a bag has an attribute
+func hasAttribute(attributes []pkcs12Attribute, id asn1.ObjectIdentifier) bool {
+	for _, attribute := range attributes {
+		if attribute.ID.Equal(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyMAC checks the integrity of the archive, which fails when the
+// password is wrong
+func verifyMAC(mac *macData, content, secret []byte) error {
+	h := digestHash(mac.Mac.Algorithm.Algorithm, false)
+	if h == nil {
+		return fmt.Errorf("unsupported PKCS#12 MAC algorithm %s", mac.Mac.Algorithm.Algorithm)
+	}
+	if err := checkIterations(mac.Iterations); err != nil {
+		return err
+	}
+	key := pkcs12KDF(h, mac.MacSalt, secret, mac.Iterations, 3, h().Size())
+	m := hmac.New(h, key)
+	m.Write(content)
+	if !hmac.Equal(m.Sum(nil), mac.Mac.Digest) {
+		return errPKCS12Password
+	}
+	return nil
+}
+
+// decryptContent decrypts an EncryptedData structure. Secret is the
+// password as used by the PKCS#12 algorithms, while PBES2 uses it as is.
+func decryptContent(data, secret []byte, password string) ([]byte, error) {
+	var ed encryptedData
+	if err := unmarshalBER(data, &ed); err != nil {
+		return nil, fmt.Errorf("invalid PKCS#12 encrypted data: %w", err)
+	}
+	info := ed.EncryptedContentInfo
+	ciphertext, err := octets(info.EncryptedContent)
+	if err != nil {
+		return nil, err
+	}
+
+	block, iv, err := pbeCipher(info.ContentEncryptionAlgorithm, secret, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid PKCS#12 encrypted data length")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// Remove the PKCS#7 padding, whose check fails with a wrong key
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > block.BlockSize() || padding > len(plaintext) {
+		return nil, errPKCS12Password
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errPKCS12Password
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// pbeCipher derives the cipher and IV of a password-based encryption
+// algorithm
+func pbeCipher(algorithm pkix.AlgorithmIdentifier, secret []byte, password string) (cipher.Block, []byte, error) {
+	id := algorithm.Algorithm
+	if id.Equal(oidPBES2) {
+		return pbes2Cipher(algorithm.Parameters.FullBytes, []byte(password))
+	}
+
+	var params pbeParams
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, nil, fmt.Errorf("invalid PKCS#12 encryption parameters: %w", err)
+	}
+	if err := checkIterations(params.Iterations); err != nil {
+		return nil, nil, err
+	}
+	derive := func(purpose byte, size int) []byte {
+		return pkcs12KDF(sha1.New, params.Salt, secret, params.Iterations, purpose, size)
+	}
+
+	var block cipher.Block
+	var err error
+	switch {
+	case id.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		block, err = des.NewTripleDESCipher(derive(1, 24))
+	case id.Equal(oidPBEWithSHAAnd128BitRC2CBC):
+		block, err = newRC2Cipher(derive(1, 16), 128)
+	case id.Equal(oidPBEWithSHAAnd40BitRC2CBC):
+		block, err = newRC2Cipher(derive(1, 5), 40)
+	default:
+		return nil, nil, fmt.Errorf("unsupported PKCS#12 encryption algorithm %s", id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, derive(2, block.BlockSize()), nil
+}
+
+// pbes2Cipher derives the cipher and IV of PBES2 with PBKDF2 (RFC 8018)
+func pbes2Cipher(data, password []byte) (cipher.Block, []byte, error) {
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(data, &params); err != nil {
+		return nil, nil, fmt.Errorf("invalid PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, nil, fmt.Errorf("unsupported PBES2 key derivation function %s", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, nil, fmt.Errorf("invalid PBKDF2 parameters: %w", err)
+	}
+	if err := checkIterations(kdf.Iterations); err != nil {
+		return nil, nil, err
+	}
+	if kdf.Salt.Tag != asn1.TagOctetString {
+		return nil, nil, errors.New("unsupported PBKDF2 salt")
+	}
+	prf := sha1.New
+	if len(kdf.PRF.Algorithm) > 0 {
+		if prf = digestHash(kdf.PRF.Algorithm, true); prf == nil {
+			return nil, nil, fmt.Errorf("unsupported PBKDF2 function %s", kdf.PRF.Algorithm)
+		}
+	}
+
+	scheme := params.EncryptionScheme
+	var iv []byte
+	if _, err := asn1.Unmarshal(scheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, fmt.Errorf("invalid PBES2 IV: %w", err)
+	}
+	var size int
+	newCipher := aes.NewCipher
+	switch {
+	case scheme.Algorithm.Equal(oidAES128CBC):
+		size = 16
+	case scheme.Algorithm.Equal(oidAES192CBC):
+		size = 24
+	case scheme.Algorithm.Equal(oidAES256CBC):
+		size = 32
+	case scheme.Algorithm.Equal(oidDESEDE3CBC):
+		size, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, nil, fmt.Errorf("unsupported PBES2 encryption scheme %s", scheme.Algorithm)
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != size {
+		return nil, nil, fmt.Errorf("invalid PBKDF2 key length %d", kdf.KeyLength)
+	}
+
+	block, err := newCipher(pbkdf2Key(prf, password, kdf.Salt.Bytes, kdf.Iterations, size))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, nil, errors.New("invalid PBES2 IV length")
+	}
+	return block, iv, nil
+}
+
+// checkIterations rejects iteration counts of key derivation functions
+// outside 1 to maxIterations
+func checkIterations(n int) error {
+	if n < 1 || n > maxIterations {
+		return fmt.Errorf("unsupported PKCS#12 iteration count %d", n)
+	}
+	return nil
+}
+
+// digestHash returns the hash function of a digest or HMAC algorithm
+func digestHash(id asn1.ObjectIdentifier, isHMAC bool) func() hash.Hash {
+	for _, d := range digests {
+		if (!isHMAC && id.Equal(d.digest)) || (isHMAC && id.Equal(d.hmac)) {
+			return d.hash
+		}
+	}
+	return nil
+}
+
+// pkcs12KDF derives key material from a password as described in RFC 7292,
+// appendix B.2. Purpose is 1 for keys, 2 for IVs and 3 for MAC keys.
+func pkcs12KDF(h func() hash.Hash, salt, password []byte, iterations int, purpose byte, size int) []byte {
+	digest := h()
+	u, v := digest.Size(), digest.BlockSize()
+
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	prefix := bytes.Repeat([]byte{purpose}, v)
+	input := append(fill(salt), fill(password)...)
+
+	var out []byte
+	for {
+		digest.Reset()
+		digest.Write(prefix)
+		digest.Write(input)
+		a := digest.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			digest.Reset()
+			digest.Write(a)
+			a = digest.Sum(nil)
+		}
+		out = append(out, a...)
+		if len(out) >= size {
+			return out[:size]
+		}
+
+		// Add a repeated to v bytes, plus one, to each v byte block of input
+		for j := 0; j < len(input); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(input[j+k]) + int(a[k%u]) + carry
+				input[j+k], carry = byte(sum), sum>>8
+			}
+		}
+	}
+}
+
+// pbkdf2Key derives a key with PBKDF2 (RFC 8018)
+func pbkdf2Key(h func() hash.Hash, password, salt []byte, iterations, size int) []byte {
+	prf := hmac.New(h, password)
+	var out []byte
+	for block := uint32(1); len(out) < size; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:size]
+}
+
+// bmpString encodes a password as a NUL terminated UTF-16 big endian
+// string, as the PKCS#12 key derivation expects
+func bmpString(s string) []byte {
+	var out []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return append(out, 0, 0)
+}
+
+// explicit returns the element inside an [0] EXPLICIT element
+func explicit(v asn1.RawValue) (asn1.RawValue, error) {
+	var inner asn1.RawValue
+	if v.Class != asn1.ClassContextSpecific || v.Tag != 0 {
+		return inner, errors.New("expected an explicitly tagged element")
+	}
+	_, err := asn1.Unmarshal(v.Bytes, &inner)
+	return inner, err
+}
+
+// explicitOctets returns the contents of an OCTET STRING inside an [0]
+// EXPLICIT element
+func explicitOctets(v asn1.RawValue) ([]byte, error) {
+	inner, err := explicit(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PKCS#12 content: %w", err)
+	}
+	return octets(inner)
+}
+
+// octets returns the contents of an OCTET STRING, which BER allows to be
+// split into several constructed parts
+func octets(v asn1.RawValue) ([]byte, error) {
+	if !v.IsCompound {
+		return v.Bytes, nil
+	}
+	var out []byte
+	rest := v.Bytes
+	for len(rest) > 0 {
+		var part asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &part); err != nil {
+			return nil, fmt.Errorf("invalid PKCS#12 octet string: %w", err)
+		}
+		data, err := octets(part)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, data...)
+	}
+	return out, nil
+}
+
+// unmarshalBER decodes BER, as some tools write it, by converting it to the
+// DER that encoding/asn1 expects first
+func unmarshalBER(data []byte, v interface{}) error {
+	der, _, err := berToDER(data)
+	if err != nil {
+		return err
+	}
+	_, err = asn1.Unmarshal(der, v)
+	return err
+}
+
+// berToDER re-encodes the first element of data with definite, minimal
+// lengths and constructed OCTET STRINGs joined, and returns the rest
+func berToDER(data []byte) (der, rest []byte, err error) {
+	if len(data) < 2 {
+		return nil, nil, errors.New("truncated ASN.1 element")
+	}
+	tagLen := 1
+	if data[0]&0x1f == 0x1f {
+		for tagLen < len(data) && data[tagLen]&0x80 != 0 {
+			tagLen++
+		}
+		tagLen++
+	}
+	if tagLen >= len(data) {
+		return nil, nil, errors.New("truncated ASN.1 tag")
+	}
+	tag := append([]byte{}, data[:tagLen]...)
+	constructed := data[0]&0x20 != 0
+	pos := tagLen + 1
+
+	var content []byte
+	if lengthByte := data[tagLen]; lengthByte == 0x80 {
+		// Indefinite length: elements up to an end-of-contents marker
+		if !constructed {
+			return nil, nil, errors.New("indefinite length of a primitive ASN.1 element")
+		}
+		for {
+			if pos+2 > len(data) {
+				return nil, nil, errors.New("missing end of ASN.1 contents")
+			}
+			if data[pos] == 0 && data[pos+1] == 0 {
+				pos += 2
+				break
+			}
+			child, childRest, err := berToDER(data[pos:])
+			if err != nil {
+				return nil, nil, err
+			}
+			content = append(content, child...)
+			pos = len(data) - len(childRest)
+		}
+	} else {
+		length := int(lengthByte)
+		if lengthByte&0x80 != 0 {
+			count := int(lengthByte & 0x7f)
+			if count > 4 || pos+count > len(data) {
+				return nil, nil, errors.New("invalid ASN.1 length")
+			}
+			length = 0
+			for _, b := range data[pos : pos+count] {
+				length = length<<8 | int(b)
+			}
+			pos += count
+		}
+		if length < 0 || pos+length > len(data) {
+			return nil, nil, errors.New("truncated ASN.1 element")
+		}
+		content = data[pos : pos+length]
+		pos += length
+		if constructed {
+			var children []byte
+			for remaining := content; len(remaining) > 0; {
+				child, childRest, err := berToDER(remaining)
+				if err != nil {
+					return nil, nil, err
+				}
+				children = append(children, child...)
+				remaining = childRest
+			}
+			content = children
+		}
+	}
+
+	// A constructed OCTET STRING becomes a primitive one
+	if tag[0] == 0x24 && len(tag) == 1 {
+		joined, err := octets(asn1.RawValue{IsCompound: true, Bytes: content})
+		if err != nil {
+			return nil, nil, err
+		}
+		tag[0], content = 0x04, joined
+	}
+
+	der = append(tag, derLength(len(content))...)
+	return append(der, content...), data[pos:], nil
+}
+
+// derLength encodes a length in DER
+func derLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
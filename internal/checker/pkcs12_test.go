@@ -0,0 +1,155 @@
+package checker
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPKCS12KDF(t *testing.T) {
+	// The SHA-1 vectors are those of the Bouncy Castle PKCS#12 tests; all
+	// were checked with "openssl kdf PKCS12KDF"
+	tests := []struct {
+		sha256     bool
+		password   string
+		salt       string
+		iterations int
+		purpose    byte
+		want       string
+	}{
+		{false, "smeg", "0a58cf64530d823f", 1, 1, "8aaae6297b6cb04642ab5b077851284eb7128f1a2a7fbca3"},
+		{false, "smeg", "0a58cf64530d823f", 1, 2, "79993dfe048d3b76"},
+		{false, "queeg", "1682c0fc5b3f7ec5", 1000, 1, "483dd6e919d7de2e8e648ba8f862f3fbfbdc2bcb2c02957f"},
+		{false, "queeg", "1682c0fc5b3f7ec5", 1000, 2, "9d461d1b00355c50"},
+		{false, "smeg", "3d83c0e4546ac140", 1, 3, "8d967d88f6caa9d714800ab3d48051d63f73a312"},
+		{true, "s3cret", "0102030405060708", 2048, 1, "06e5454f32130b6d6a77a59e113eb833902be44477ebaec58dcdee62d8331002"},
+		{true, "", "0102030405060708090a0b0c0d0e0f10", 3, 3, "608749f9b1dbc441d027a12013ab9f6b7045d11ad9f8cfe5890196e0b60fba9bc087c470d9c66269"},
+	}
+	for _, tt := range tests {
+		h := sha1.New
+		if tt.sha256 {
+			h = sha256.New
+		}
+		salt, _ := hex.DecodeString(tt.salt)
+		want, _ := hex.DecodeString(tt.want)
+		got := pkcs12KDF(h, salt, bmpString(tt.password), tt.iterations, tt.purpose, len(want))
+		if !bytes.Equal(got, want) {
+			t.Errorf("%q, purpose %d: got %x, want %x", tt.password, tt.purpose, got, want)
+		}
+	}
+}
+
+func TestBMPString(t *testing.T) {
+	if got := hex.EncodeToString(bmpString("smeg")); got != "0073006d006500670000" {
+		t.Errorf("bmpString = %s", got)
+	}
+}
+
+func TestDecodePKCS12BER(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "modern.p12"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rewrite the outer SEQUENCE with an indefinite length, as some tools
+	// write it
+	if data[0] != 0x30 || data[1] != 0x82 {
+		t.Fatalf("unexpected header %x", data[:2])
+	}
+	ber := append(append([]byte{0x30, 0x80}, data[4:]...), 0x00, 0x00)
+
+	certs, err := decodePKCS12(ber, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 || certs[0].Subject.CommonName != "leaf.example.com" {
+		t.Errorf("certificates = %v", certs)
+	}
+}
+
+func TestCheckIterations(t *testing.T) {
+	for _, n := range []int{1, 2048, maxIterations} {
+		if err := checkIterations(n); err != nil {
+			t.Errorf("checkIterations(%d): %v", n, err)
+		}
+	}
+	for _, n := range []int{0, -1, maxIterations + 1, 1 << 30} {
+		if err := checkIterations(n); err == nil {
+			t.Errorf("checkIterations(%d) succeeded", n)
+		}
+	}
+}
+
+func TestBERToDER(t *testing.T) {
+	tests := []struct {
+		name, ber, der string
+	}{
+		{"definite", "3003020101", "3003020101"},
+		{"indefinite", "30800201010000", "3003020101"},
+		{"constructed octets", "2480040261620401630000", "0403616263"},
+		{"nested", "3080308002010100000000", "30053003020101"},
+	}
+	for _, tt := range tests {
+		ber, _ := hex.DecodeString(tt.ber)
+		der, rest, err := berToDER(ber)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(der); got != tt.der || len(rest) != 0 {
+			t.Errorf("%s: got %s (rest %x), want %s", tt.name, got, rest, tt.der)
+		}
+	}
+
+	for _, bad := range []string{"30", "3080020101", "0480", "3005020101"} {
+		data, _ := hex.DecodeString(bad)
+		if _, _, err := berToDER(data); err == nil {
+			t.Errorf("berToDER(%s) succeeded", bad)
+		}
+	}
+}
+
+// The PKCS#12 and BER decoders read untrusted files, so they are fuzzed
+// against crashes and hangs, seeded with the fixtures. Run them with
+// go test -fuzz=FuzzDecodePKCS12 ./internal/checker.
+
+func FuzzDecodePKCS12(f *testing.F) {
+	for _, name := range []string{"modern.p12", "legacy.p12", "3des.p12", "nomac.p12", "unencrypted.p12", "nopass.pfx"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data, "s3cret")
+		f.Add(data, "")
+	}
+	f.Fuzz(func(t *testing.T, data []byte, password string) {
+		certs, err := decodePKCS12(data, password)
+		if err == nil && len(certs) == 0 {
+			t.Error("no error and no certificates")
+		}
+	})
+}
+
+func FuzzBERToDER(f *testing.F) {
+	for _, seed := range []string{"3003020101", "30800201010000", "2480040261620401630000", "3080308002010100000000"} {
+		data, _ := hex.DecodeString(seed)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		der, rest, err := berToDER(data)
+		if err != nil {
+			return
+		}
+		if len(der)+len(rest) > 2*len(data)+16 {
+			t.Errorf("%d bytes of input became %d bytes of DER", len(data), len(der))
+		}
+		// DER is valid BER and converts to itself
+		again, _, err := berToDER(der)
+		if err != nil || !bytes.Equal(again, der) {
+			t.Errorf("converting %x again: %x, %v", der, again, err)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// proxyFor returns the proxy to reach address through, or nil to connect
+// directly. Without a proxy setting the HTTPS_PROXY and NO_PROXY
+// environment variables decide.
+func proxyFor(setting, address string) (*url.URL, error) {
+	switch setting {
+	case "none":
+		return nil, nil
+	case "":
+		return http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: address}})
+	}
+	return config.ParseProxy(setting)
+}
+
+// dialProxy connects to address through an HTTP CONNECT or SOCKS5 proxy.
+// As with curl, a socks5 proxy is sent the address resolved locally and a
+// socks5h proxy the host name. The proxy negotiation is bounded by the
+// deadline of ctx.
+func dialProxy(ctx context.Context, dialer *net.Dialer, proxy *url.URL, address string) (net.Conn, error) {
+	if proxy.Scheme == "socks5" {
+		resolved, err := resolveAddress(ctx, dialer.Resolver, address)
+		if err != nil {
+			return nil, err
+		}
+		address = resolved
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress(proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tunnel := conn
+	switch proxy.Scheme {
+	case "https":
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err == nil {
+			tunnel, err = connectHTTP(tlsConn, proxy, address)
+		}
+	case "http":
+		tunnel, err = connectHTTP(conn, proxy, address)
+	default: // socks5, socks5h
+		err = connectSOCKS5(conn, proxy, address)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", proxy.Redacted(), err)
+	}
+	tunnel.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+// resolveAddress replaces the host name of address with its first IP
+// address
+func resolveAddress(ctx context.Context, resolver *net.Resolver, address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return address, nil
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no addresses found for %s", host)
+	}
+	return net.JoinHostPort(addrs[0].IP.String(), port), nil
+}
+
+// proxyAddress returns host:port of a proxy, using the scheme's default
+// port when none is given
+func proxyAddress(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		switch proxy.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			port = "1080"
+		}
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// connectHTTP opens a tunnel with an HTTP CONNECT request, authenticating
+// with basic auth if the proxy URL has credentials
+func connectHTTP(conn net.Conn, proxy *url.URL, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		req.SetBasicAuth(proxy.User.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send CONNECT request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CONNECT failed: %s", resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were already read into
+// a buffer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// socksErrors describes the SOCKS5 reply codes
+var socksErrors = map[byte]string{
+	1: "general failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// connectSOCKS5 opens a tunnel through a SOCKS5 proxy (RFC 1928), with
+// username/password authentication (RFC 1929) if the proxy URL has
+// credentials. A host name in address is resolved by the proxy.
+func connectSOCKS5(conn net.Conn, proxy *url.URL, address string) error {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portText)
+	}
+
+	methods := []byte{0x00} // no authentication
+	if proxy.User != nil {
+		methods = []byte{0x02} // username/password
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS greeting: %w", err)
+	}
+	if reply[0] != 0x05 {
+		return errors.New("not a SOCKS5 proxy")
+	}
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if err := socksAuthenticate(conn, proxy.User); err != nil {
+			return err
+		}
+	default:
+		return errors.New("no acceptable SOCKS authentication method")
+	}
+
+	req := []byte{0x05, 0x01, 0x00} // CONNECT
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("host name %q is too long", host)
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, 0x01)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, 0x04)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: version, status, reserved, then the bound address
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("failed to read SOCKS reply: %w", err)
+	}
+	if header[1] != 0x00 {
+		msg, ok := socksErrors[header[1]]
+		if !ok {
+			msg = fmt.Sprintf("error %d", header[1])
+		}
+		return fmt.Errorf("SOCKS connect failed: %s", msg)
+	}
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("unknown SOCKS address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
+
+// socksAuthenticate performs SOCKS5 username/password authentication
+func socksAuthenticate(conn net.Conn, user *url.Userinfo) error {
+	username := user.Username()
+	password, _ := user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("SOCKS username and password must be at most 255 bytes")
+	}
+
+	req := []byte{0x01, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS authentication reply: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return errors.New("SOCKS authentication failed")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// listen runs handle for every connection to a local listener and returns
+// its address
+func listen(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// tunnel copies data between a client and the target address
+func tunnel(client io.ReadWriter, address string) {
+	upstream, err := net.Dial("tcp", address)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	go io.Copy(upstream, client)
+	io.Copy(client, upstream)
+}
+
+// connectProxy is an HTTP CONNECT proxy requiring the credentials u:pw
+func connectProxy(t *testing.T) string {
+	return listen(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		req, err := http.ReadRequest(reader)
+		if err != nil || req.Method != http.MethodConnect {
+			return
+		}
+		auth := &http.Request{Header: http.Header{"Authorization": req.Header["Proxy-Authorization"]}}
+		if user, password, ok := auth.BasicAuth(); !ok || user != "u" || password != "pw" {
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n")
+		tunnel(struct {
+			io.Reader
+			io.Writer
+		}{reader, conn}, req.Host)
+	})
+}
+
+// socksProxy is a SOCKS5 proxy that reports the requested host to hosts.
+// With credentials set, clients must authenticate with them.
+func socksProxy(t *testing.T, username, password string, hosts chan<- string) string {
+	return listen(t, func(conn net.Conn) {
+		greeting := make([]byte, 2)
+		if _, err := io.ReadFull(conn, greeting); err != nil {
+			return
+		}
+		io.ReadFull(conn, make([]byte, greeting[1]))
+		if username == "" {
+			conn.Write([]byte{0x05, 0x00})
+		} else {
+			conn.Write([]byte{0x05, 0x02})
+			if user, pass := readSOCKSAuth(conn); user != username || pass != password {
+				conn.Write([]byte{0x01, 0x01})
+				return
+			}
+			conn.Write([]byte{0x01, 0x00})
+		}
+
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		var host string
+		switch header[3] {
+		case 0x01, 0x04:
+			ip := make(net.IP, map[byte]int{0x01: net.IPv4len, 0x04: net.IPv6len}[header[3]])
+			io.ReadFull(conn, ip)
+			host = ip.String()
+		case 0x03:
+			length := make([]byte, 1)
+			io.ReadFull(conn, length)
+			name := make([]byte, length[0])
+			io.ReadFull(conn, name)
+			host = string(name)
+		}
+		port := make([]byte, 2)
+		io.ReadFull(conn, port)
+		hosts <- host
+
+		address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+		if host == "localhost" {
+			address = net.JoinHostPort("127.0.0.1", strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+		}
+		upstream, err := net.Dial("tcp", address)
+		if err != nil {
+			conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer upstream.Close()
+		conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+		go io.Copy(upstream, conn)
+		io.Copy(conn, upstream)
+	})
+}
+
+func readSOCKSAuth(conn net.Conn) (string, string) {
+	field := func() string {
+		length := make([]byte, 1)
+		io.ReadFull(conn, length)
+		value := make([]byte, length[0])
+		io.ReadFull(conn, value)
+		return string(value)
+	}
+	io.ReadFull(conn, make([]byte, 1)) // version
+	return field(), field()
+}
+
+// errorText returns the error of a check result, or "" if it succeeded
+func errorText(r config.CheckResult) string {
+	if r.Error == nil {
+		return ""
+	}
+	return r.Error.Error()
+}
+
+func TestCheckThroughConnectProxy(t *testing.T) {
+	proxy := connectProxy(t)
+	domain := config.DomainConfig{Host: "127.0.0.1", Port: tlsTarget(t), InsecureSkipVerify: true}
+
+	domain.Connection.Proxy = "http://u:pw@" + proxy
+	if r := NewChecker().CheckDomain(context.Background(), domain); !r.Success {
+		t.Errorf("check failed: %v", r.Error)
+	}
+
+	domain.Connection.Proxy = "http://u:wrong@" + proxy
+	r := NewChecker().CheckDomain(context.Background(), domain)
+	if r.Success || !strings.Contains(errorText(r), "407") {
+		t.Errorf("error = %v, want a 407", r.Error)
+	}
+	if strings.Contains(errorText(r), "wrong") {
+		t.Errorf("error leaks the proxy password: %q", r.Error)
+	}
+}
+
+func TestCheckThroughSOCKSProxy(t *testing.T) {
+	hosts := make(chan string, 1)
+	proxy := socksProxy(t, "su", "sp", hosts)
+	domain := config.DomainConfig{Host: "localhost", Port: tlsTarget(t), InsecureSkipVerify: true}
+
+	domain.Connection.Proxy = "socks5h://su:sp@" + proxy
+	if r := NewChecker().CheckDomain(context.Background(), domain); !r.Success {
+		t.Errorf("socks5h check failed: %v", r.Error)
+	}
+	if host := <-hosts; host != "localhost" {
+		t.Errorf("socks5h sent %q, want the host name", host)
+	}
+
+	// socks5 resolves the name locally and sends the proxy an address
+	domain.Connection.Proxy = "socks5://su:sp@" + proxy
+	NewChecker().CheckDomain(context.Background(), domain)
+	if host := <-hosts; net.ParseIP(host) == nil || !net.ParseIP(host).IsLoopback() {
+		t.Errorf("socks5 sent %q, want a loopback address", host)
+	}
+
+	domain.Connection.Proxy = "socks5h://su:wrong@" + proxy
+	if r := NewChecker().CheckDomain(context.Background(), domain); !strings.Contains(errorText(r), "SOCKS authentication failed") {
+		t.Errorf("error = %v", r.Error)
+	}
+}
+
+func TestCheckSOCKSConnectFailure(t *testing.T) {
+	hosts := make(chan string, 1)
+	proxy := socksProxy(t, "", "", hosts)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	domain := config.DomainConfig{Host: "127.0.0.1", Port: port, Connection: config.ConnectionConfig{Proxy: "socks5://" + proxy}}
+	if r := NewChecker().CheckDomain(context.Background(), domain); !strings.Contains(errorText(r), "SOCKS connect failed: connection refused") {
+		t.Errorf("error = %v", r.Error)
+	}
+	if host := <-hosts; host != "127.0.0.1" {
+		t.Errorf("host = %q", host)
+	}
+}
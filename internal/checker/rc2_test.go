@@ -0,0 +1,51 @@
+package checker
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// The test vectors of RFC 2268, section 5
+func TestRC2(t *testing.T) {
+	tests := []struct {
+		key        string
+		bits       int
+		plaintext  string
+		ciphertext string
+	}{
+		{"0000000000000000", 63, "0000000000000000", "ebb773f993278eff"},
+		{"ffffffffffffffff", 64, "ffffffffffffffff", "278b27e42e2f0d49"},
+		{"3000000000000000", 64, "1000000000000001", "30649edf9be7d2c2"},
+		{"88", 64, "0000000000000000", "61a8a244adacccf0"},
+		{"88bca90e90875a", 64, "0000000000000000", "6ccf4308974c267f"},
+		{"88bca90e90875a7f0f79c384627bafb2", 64, "0000000000000000", "1a807d272bbe5db1"},
+		{"88bca90e90875a7f0f79c384627bafb2", 128, "0000000000000000", "2269552ab0f85ca6"},
+		{"88bca90e90875a7f0f79c384627bafb216f80a6f85920584c42fceb0be255daf1e", 129, "0000000000000000", "5b78d3a43dfff1f1"},
+	}
+	for _, tt := range tests {
+		key, _ := hex.DecodeString(tt.key)
+		plaintext, _ := hex.DecodeString(tt.plaintext)
+		block, err := newRC2Cipher(key, tt.bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out := make([]byte, block.BlockSize())
+		block.Encrypt(out, plaintext)
+		if got := hex.EncodeToString(out); got != tt.ciphertext {
+			t.Errorf("key %s/%d: encrypted to %s, want %s", tt.key, tt.bits, got, tt.ciphertext)
+		}
+		block.Decrypt(out, out)
+		if got := hex.EncodeToString(out); got != tt.plaintext {
+			t.Errorf("key %s/%d: decrypted to %s, want %s", tt.key, tt.bits, got, tt.plaintext)
+		}
+	}
+}
+
+func TestRC2KeySize(t *testing.T) {
+	for _, tt := range []struct{ size, bits int }{{0, 64}, {129, 64}, {8, 0}, {8, 1025}} {
+		if _, err := newRC2Cipher(make([]byte, tt.size), tt.bits); err == nil {
+			t.Errorf("newRC2Cipher accepted a %d byte key with %d bits", tt.size, tt.bits)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+#!/bin/sh
+# Regenerates the certificate file fixtures with OpenSSL 3. The leaf
+# certificate is valid for 100 years so the fixtures don't expire.
+set -e
+cd "$(dirname "$0")"
+rm -f *.pem *.der *.p12 *.pfx
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+openssl req -x509 -newkey rsa:2048 -nodes -keyout "$tmp/ca.key" -out ca.pem -days 36600 -subj "/CN=Test CA" 2>/dev/null
+openssl req -newkey rsa:2048 -nodes -keyout "$tmp/leaf.key" -out "$tmp/leaf.csr" -subj "/CN=leaf.example.com" 2>/dev/null
+openssl x509 -req -in "$tmp/leaf.csr" -CA ca.pem -CAkey "$tmp/ca.key" -CAcreateserial -CAserial "$tmp/ca.srl" -out leaf.pem -days 36500 2>/dev/null
+
+cat leaf.pem ca.pem > chain.pem
+cat "$tmp/leaf.key" leaf.pem > key-and-cert.pem
+openssl x509 -in leaf.pem -outform DER -out leaf.der
+openssl x509 -in ca.pem -outform DER -out "$tmp/ca.der"
+cat leaf.der "$tmp/ca.der" > chain.der
+
+# PKCS#12 archives as written by current and older OpenSSL versions, all
+# with the password s3cret unless noted
+pkcs12() { openssl pkcs12 -export -inkey "$tmp/leaf.key" -in leaf.pem "$@"; }
+pkcs12 -certfile ca.pem -passout pass:s3cret -out modern.p12
+pkcs12 -certfile ca.pem -passout pass:s3cret -out legacy.p12 -legacy
+pkcs12 -certfile ca.pem -passout pass:s3cret -out 3des.p12 -certpbe PBE-SHA1-3DES -keypbe PBE-SHA1-3DES -macalg sha1
+pkcs12 -passout pass:s3cret -out nomac.p12 -nomac
+pkcs12 -passout pass:s3cret -out unencrypted.p12 -certpbe NONE
+pkcs12 -passout pass: -out nopass.pfx
@@ -1,37 +1,42 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig reads and parses the YAML configuration file
+// LoadConfig reads and parses the YAML configuration file together with the
+// files it includes. ${VAR} references and *_file secrets are resolved
+// before the configuration is decoded.
+//
+// Unknown keys are rejected, and all problems found are reported together,
+// each prefixed with the file and line it was found at.
 func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	cfg := DefaultConfig()
+	l := newLoader(cfg)
+	if err := l.loadFile(path); err != nil {
+		return nil, err
 	}
 
-	cfg := DefaultConfig()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	errs := append(l.errs, l.validate()...)
+	for i := range cfg.Domains {
+		if err := cfg.finishDomain(&cfg.Domains[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	cfg.static = cfg.Domains
+	if err := cfg.RefreshDomains(); err != nil {
+		return nil, err
 	}
 
 	// Validate required fields
 	if len(cfg.Domains) == 0 {
 		return nil, fmt.Errorf("no domains configured")
 	}
-	for i, d := range cfg.Domains {
-		if d.Host == "" {
-			return nil, fmt.Errorf("domain %d missing host", i)
-		}
-		if d.Port == 0 {
-			cfg.Domains[i].Port = 443
-		}
-	}
 
 	// Ensure state file path is absolute
 	if cfg.State.File != "" && !filepath.IsAbs(cfg.State.File) {
@@ -43,4 +48,116 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	return cfg, nil
-}
\ No newline at end of file
+}
+
+// WatchPaths returns the files the configuration was loaded from and the
+// directories whose contents decide which files are loaded. A change to any
+// of them means the configuration should be loaded again.
+func (c *Config) WatchPaths() []string {
+	return append(append([]string{}, c.Files...), c.dirs...)
+}
+
+// RefreshDomains reloads the domain sources and rebuilds Domains from the
+// domains of the config files followed by the loaded ones. Endpoints that
+// are already defined are skipped, so the first definition wins. Domains
+// is left unchanged on error.
+func (c *Config) RefreshDomains() error {
+	if len(c.DomainSources) == 0 {
+		return nil
+	}
+
+	domains := append([]DomainConfig{}, c.static...)
+	seen := make(map[string]bool)
+	for _, d := range domains {
+		seen[d.Endpoint()] = true
+	}
+	for _, src := range c.DomainSources {
+		loaded, err := src.Load()
+		if err != nil {
+			return err
+		}
+		for _, d := range loaded {
+			if err := c.finishDomain(&d); err != nil {
+				return err
+			}
+			endpoint := d.Endpoint()
+			if seen[endpoint] {
+				continue
+			}
+			seen[endpoint] = true
+			domains = append(domains, d)
+		}
+	}
+
+	c.Domains = domains
+	return nil
+}
+
+// finishDomain validates a domain and fills in the global settings for
+// anything it does not override
+func (c *Config) finishDomain(d *DomainConfig) error {
+	var errs []error
+	switch {
+	case d.Host == "" && d.File == "":
+		errs = append(errs, fmt.Errorf("%s: domain missing host or file", d.Source))
+	case d.Host != "" && d.File != "":
+		errs = append(errs, fmt.Errorf("%s: domain %s sets both host and file", d.Source, d.Host))
+	case d.File != "":
+		errs = append(errs, checkFileDomain(d)...)
+	case d.Port == 0:
+		applyDefault(d, "Port")
+	case d.Port < 1 || d.Port > 65535:
+		errs = append(errs, fmt.Errorf("%s: domain %s has invalid port %d", d.Source, d.Host, d.Port))
+	}
+	if d.Host != "" && d.Password != "" {
+		errs = append(errs, fmt.Errorf("%s: domain %s: password only applies to certificate files", d.Source, d.Host))
+	}
+	if d.CooldownHours != nil && *d.CooldownHours < 0 {
+		errs = append(errs, fmt.Errorf("%s: domain %s has negative cooldown_hours", d.Source, d.Key()))
+	}
+	if err := checkReminderDays(d.ReminderDays); err != nil {
+		errs = append(errs, fmt.Errorf("%s: domain %s: %w", d.Source, d.Key(), err))
+	}
+	if err := checkConnection(d.Connection); err != nil {
+		errs = append(errs, fmt.Errorf("%s: domain %s: %w", d.Source, d.Key(), err))
+	}
+	if d.Connection.NotifierTimeout != 0 {
+		errs = append(errs, fmt.Errorf("%s: domain %s: notifier_timeout can only be set globally", d.Source, d.Key()))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if len(d.ReminderDays) == 0 {
+		d.ReminderDays = c.ReminderDays
+	}
+	if d.CooldownHours == nil {
+		cooldown := c.State.CooldownHours
+		d.CooldownHours = &cooldown
+	}
+	d.Connection = d.Connection.inherit(c.Connection)
+	return nil
+}
+
+// inherit fills in the unset fields of c from the global settings
+func (c ConnectionConfig) inherit(global ConnectionConfig) ConnectionConfig {
+	if c.DialTimeout == 0 {
+		c.DialTimeout = global.DialTimeout
+	}
+	if c.HandshakeTimeout == 0 {
+		c.HandshakeTimeout = global.HandshakeTimeout
+	}
+	if c.Retries == 0 {
+		c.Retries = global.Retries
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = global.RetryBackoff
+	}
+	if c.SourceAddress == "" {
+		c.SourceAddress = global.SourceAddress
+	}
+	if c.Proxy == "" {
+		c.Proxy = global.Proxy
+	}
+	return c
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files below dir, with parent directories as needed
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadFiles writes files to a temporary directory and loads its config.yaml
+func loadFiles(t *testing.T, files map[string]string) (*Config, error) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	return LoadConfig(filepath.Join(dir, "config.yaml"))
+}
+
+// notifierSettings decodes the settings of the named notifier
+func notifierSettings(t *testing.T, cfg *Config, name string, v interface{}) {
+	t.Helper()
+	for _, n := range cfg.Notifications {
+		if n.Name == name {
+			if err := n.Decode(v); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("notifier %q not found", name)
+}
+
+func TestLoadConfigExpandsVariables(t *testing.T) {
+	t.Setenv("MONITOR_PORT", "8443")
+	t.Setenv("HOOK_TOKEN", "s3cret")
+	cfg, err := loadFiles(t, map[string]string{
+		"config.yaml": `
+domains:
+  - host: example.com
+    port: ${MONITOR_PORT}
+    name: ${UNSET_NAME:-fallback}
+notifications:
+  webhook:
+    enabled: true
+    url: https://hooks.example.com/$${literal}
+    headers:
+      Authorization: Bearer ${HOOK_TOKEN}
+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cfg.Domains[0]; d.Port != 8443 || d.Name != "fallback" {
+		t.Errorf("domain = %+v", d)
+	}
+	var w WebhookConfig
+	notifierSettings(t, cfg, "webhook", &w)
+	if w.URL != "https://hooks.example.com/${literal}" {
+		t.Errorf("url = %q", w.URL)
+	}
+	if got := w.Headers["Authorization"]; got != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestLoadConfigReportsUnsetVariables(t *testing.T) {
+	_, err := loadFiles(t, map[string]string{
+		"config.yaml": `
+domains:
+  - host: ${UNSET_HOST_A}
+  - host: ${UNSET_HOST_B}
+`,
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"line 3: environment variable UNSET_HOST_A is not set", "line 4: environment variable UNSET_HOST_B is not set"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadConfigSecretFilesRelativeToConfig(t *testing.T) {
+	cfg, err := loadFiles(t, map[string]string{
+		"config.yaml": `
+include: [teams/a.yaml]
+domains:
+  - host: example.com
+notifications:
+  email:
+    enabled: true
+    smtp_host: mail.example.com
+    from: alerts@example.com
+    to: ops@example.com
+    username: alerts
+    password_file: secrets/smtp
+`,
+		"secrets/smtp": "smtp-password\n",
+		"teams/a.yaml": `
+notifications:
+  - name: team-a
+    type: webhook
+    url_file: hook-url
+    headers:
+      Authorization: Bearer ${file:token}
+`,
+		"teams/hook-url": "https://hooks.example.com/a\n",
+		"teams/token":    "team-token\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e EmailConfig
+	notifierSettings(t, cfg, "email", &e)
+	if e.Password != "smtp-password" {
+		t.Errorf("password = %q", e.Password)
+	}
+	var w WebhookConfig
+	notifierSettings(t, cfg, "team-a", &w)
+	if w.URL != "https://hooks.example.com/a" || w.Headers["Authorization"] != "Bearer team-token" {
+		t.Errorf("webhook = %+v", w)
+	}
+}
+
+func TestLoadConfigSecretFileConflicts(t *testing.T) {
+	_, err := loadFiles(t, map[string]string{
+		"config.yaml": `
+domains:
+  - host: example.com
+notifications:
+  webhook:
+    enabled: true
+    url: https://hooks.example.com
+    url_file: hook-url
+`,
+		"hook-url": "https://other.example.com\n",
+	})
+	if err == nil || !strings.Contains(err.Error(), "url and url_file are mutually exclusive") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestLoadConfigTemplateFilesRelativeToConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+include: [teams/a.yaml]
+domains:
+  - host: example.com
+`,
+		"teams/a.yaml": `
+notifications:
+  - name: team-a
+    type: slack
+    webhook_url: https://hooks.slack.com/services/a
+    templates:
+      title_file: templates/title.tmpl
+      body_file: /etc/ssl-monitor/body.tmpl
+`,
+	})
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s SlackConfig
+	notifierSettings(t, cfg, "team-a", &s)
+	if want := filepath.Join(dir, "teams", "templates", "title.tmpl"); s.Templates.TitleFile != want {
+		t.Errorf("title_file = %q, want %q", s.Templates.TitleFile, want)
+	}
+	if s.Templates.BodyFile != "/etc/ssl-monitor/body.tmpl" {
+		t.Errorf("absolute body_file changed to %q", s.Templates.BodyFile)
+	}
+}
+
+func TestLoadConfigTLSFilesRelativeToConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+domains:
+  - host: example.com
+notifications:
+  webhook:
+    enabled: true
+    url: https://hooks.example.com
+    tls:
+      cert_file: tls/client.crt
+      key_file: tls/client.key
+      ca_file: /etc/ssl/ca.pem
+`,
+	})
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w WebhookConfig
+	notifierSettings(t, cfg, "webhook", &w)
+	if w.TLS.CertFile != filepath.Join(dir, "tls", "client.crt") || w.TLS.KeyFile != filepath.Join(dir, "tls", "client.key") || w.TLS.CAFile != "/etc/ssl/ca.pem" {
+		t.Errorf("tls = %+v", w.TLS)
+	}
+}
+
+func TestLoadConfigAliasedLists(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+<<:
+  domains: &shared
+    - host: a.example.com
+    - host: b.example.com
+  routes: &routes
+    - name: drop-b
+      match: {hosts: [b.example.com]}
+      notifiers: []
+domains: *shared
+routes: *routes
+`,
+	})
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Domains) != 2 || cfg.Domains[1].Host != "b.example.com" {
+		t.Fatalf("domains = %+v", cfg.Domains)
+	}
+	if want := filepath.Join(dir, "config.yaml") + ":5"; cfg.Domains[1].Source != want {
+		t.Errorf("source = %q, want %q", cfg.Domains[1].Source, want)
+	}
+	if len(cfg.Routes) != 1 || cfg.Routes[0].Source != filepath.Join(dir, "config.yaml")+":7" {
+		t.Errorf("routes = %+v", cfg.Routes)
+	}
+}
+
+func TestLoadConfigCertificateFilesRelativeToConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+include: [teams/a.yaml]
+domains_dir: conf.d
+domains:
+  - file: certs/*.pem
+  - file: /etc/ssl/certs/site.pem
+`,
+		"teams/a.yaml": `
+<<:
+  domains:
+    - file: team.p12
+`,
+		"conf.d/b.yaml": `
+domains:
+  - file: ../shared/b.pem
+`,
+	})
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, d := range cfg.Domains {
+		files = append(files, d.File)
+	}
+	for _, want := range []string{
+		filepath.Join(dir, "certs", "*.pem"),
+		"/etc/ssl/certs/site.pem",
+		filepath.Join(dir, "teams", "team.p12"),
+		filepath.Join(dir, "shared", "b.pem"),
+	} {
+		if !containsString(files, want) {
+			t.Errorf("files %q do not include %q", files, want)
+		}
+	}
+}
+
+func TestLoadConfigCooldownHours(t *testing.T) {
+	cfg, err := loadFiles(t, map[string]string{
+		"config.yaml": `domains:
+  - host: inherit.example.com
+  - host: zero.example.com
+    cooldown_hours: 0
+domains_dir: conf.d
+state:
+  cooldown_hours: 24
+`,
+		"conf.d/team.yaml": `defaults:
+  cooldown_hours: 0
+domains:
+  - host: default.example.com
+  - host: own.example.com
+    cooldown_hours: 6
+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"inherit.example.com": 24,
+		"zero.example.com":    0,
+		"default.example.com": 0,
+		"own.example.com":     6,
+	}
+	for _, d := range cfg.Domains {
+		if d.CooldownHours == nil || *d.CooldownHours != want[d.Host] {
+			t.Errorf("%s: cooldown_hours = %v, want %d", d.Host, d.CooldownHours, want[d.Host])
+		}
+	}
+	if len(cfg.Domains) != len(want) {
+		t.Errorf("got %d domains, want %d", len(cfg.Domains), len(want))
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := WebhookConfig{Signing: WebhookSigningConfig{TimestampHeader: "X-Time"}}
+	ApplyDefaults(&cfg)
+	if cfg.Method != "POST" {
+		t.Errorf("method = %q, want POST", cfg.Method)
+	}
+	// Nested structs get their defaults, and fields already set are kept
+	if cfg.Signing.SignatureHeader != "X-Signature-256" || cfg.Signing.TimestampHeader != "X-Time" {
+		t.Errorf("signing = %+v", cfg.Signing)
+	}
+
+	exec := ExecConfig{MaxConcurrent: 4}
+	ApplyDefaults(&exec)
+	if exec.Timeout != 30 || exec.MaxConcurrent != 4 {
+		t.Errorf("timeout, max_concurrent = %d, %d, want 30, 4", exec.Timeout, exec.MaxConcurrent)
+	}
+}
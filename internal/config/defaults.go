@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ApplyDefaults sets every zero-valued field of the struct v points to from
+// its default tag, descending into nested structs. The tags are the single
+// source of the defaults, which the config schema documents as well.
+func ApplyDefaults(v interface{}) {
+	applyDefaults(reflect.ValueOf(v).Elem())
+}
+
+// applyDefault sets the named field of the struct v points to from its
+// default tag when it is zero
+func applyDefault(v interface{}, name string) {
+	value := reflect.ValueOf(v).Elem()
+	field, ok := value.Type().FieldByName(name)
+	if !ok {
+		panic(fmt.Sprintf("config: %s has no field %s", value.Type(), name))
+	}
+	setDefault(field, value.FieldByIndex(field.Index))
+}
+
+func applyDefaults(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			applyDefaults(v.Field(i))
+			continue
+		}
+		setDefault(field, v.Field(i))
+	}
+}
+
+// setDefault parses the default tag of field into value when it is zero
+func setDefault(field reflect.StructField, value reflect.Value) {
+	tag, ok := field.Tag.Lookup("default")
+	if !ok || !value.IsZero() {
+		return
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(tag)
+	case reflect.Int:
+		n, err := strconv.Atoi(tag)
+		if err != nil {
+			panic(fmt.Sprintf("config: invalid default %q of %s", tag, field.Name))
+		}
+		value.SetInt(int64(n))
+	default:
+		panic(fmt.Sprintf("config: unsupported default of %s", field.Name))
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeys are the settings that can be read from a file with a "_file"
+// suffix, e.g. password_file instead of password
+var secretKeys = map[string]bool{
+	"password":      true,
+	"username":      true,
+	"token":         true,
+	"bot_token":     true,
+	"access_token":  true,
+	"app_token":     true,
+	"api_token":     true,
+	"api_key":       true,
+	"user_key":      true,
+	"routing_key":   true,
+	"client_secret": true,
+	"secret":        true,
+	"webhook_url":   true,
+	"url":           true,
+}
+
+// pathKeys are the settings naming files that notifiers use. Like secret
+// files, relative paths are resolved against the config file.
+var pathKeys = map[string]bool{
+	"title_file":     true,
+	"body_file":      true,
+	"subject_file":   true,
+	"html_body_file": true,
+	"cert_file":      true,
+	"key_file":       true,
+	"ca_file":        true,
+	"lock_file":      true,
+}
+
+// IsSecretKey reports whether a setting can also be read from a file with
+// a "_file" suffix
+func IsSecretKey(key string) bool {
+	return secretKeys[key]
+}
+
+// expandNode resolves ${VAR} references and *_file secrets in every value of
+// a parsed YAML document. Relative secret file paths are resolved against
+// dir, the directory of the config file. All problems are reported together.
+func expandNode(node *yaml.Node, dir string) error {
+	e := &expander{dir: dir}
+	e.walk(node)
+	return errors.Join(e.errs...)
+}
+
+// expander expands the values of a config file
+type expander struct {
+	dir  string  // directory of the config file
+	errs []error // problems found so far
+}
+
+// walk expands the values below node, collecting errors
+func (e *expander) walk(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			e.walk(child)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			e.walk(node.Content[i+1])
+		}
+		if err := e.resolveSecretFiles(node); err != nil {
+			e.errs = append(e.errs, err)
+		}
+		e.resolvePaths(node)
+	case yaml.ScalarNode:
+		value, err := e.expandString(node.Value)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("line %d: %w", node.Line, err))
+			return
+		}
+		if value != node.Value {
+			node.Value = value
+			// Let plain scalars resolve again, so "port: ${SMTP_PORT}" is an int
+			if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+			}
+		}
+	}
+}
+
+// resolveSecretFiles replaces "<key>_file: path" entries of a mapping with
+// "<key>: <file contents>" for the keys in secretKeys
+func (e *expander) resolveSecretFiles(node *yaml.Node) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key, ok := strings.CutSuffix(keyNode.Value, "_file")
+		if !ok || !secretKeys[key] || valueNode.Kind != yaml.ScalarNode {
+			continue
+		}
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				return fmt.Errorf("line %d: %s and %s are mutually exclusive", keyNode.Line, key, keyNode.Value)
+			}
+		}
+
+		secret, err := e.readSecretFile(valueNode.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", keyNode.Line, keyNode.Value, err)
+		}
+		keyNode.Value = key
+		valueNode.Value = secret
+		valueNode.Tag = "!!str"
+		valueNode.Style = yaml.DoubleQuotedStyle
+	}
+	return nil
+}
+
+// resolvePaths makes the values of the keys in pathKeys relative to the
+// directory of the config file
+func (e *expander) resolvePaths(node *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if pathKeys[keyNode.Value] && valueNode.Kind == yaml.ScalarNode && valueNode.Value != "" {
+			valueNode.Value = resolvePath(e.dir, valueNode.Value)
+		}
+	}
+}
+
+// readSecretFile reads a secret, dropping the trailing newline most editors
+// and secret stores add
+func (e *expander) readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(resolvePath(e.dir, path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// expandString replaces ${VAR}, ${VAR:-default} and ${file:path} references.
+// "$${" is an escaped literal "${".
+func (e *expander) expandString(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			sb.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s[i:])
+		}
+		sb.WriteString(s[:i])
+
+		value, err := e.lookupReference(s[i+2 : i+end])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(value)
+		s = s[i+end+1:]
+	}
+}
+
+// lookupReference resolves the contents of a ${...} reference
+func (e *expander) lookupReference(ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		value, err := e.readSecretFile(path)
+		if err != nil {
+			return "", fmt.Errorf("${%s}: %w", ref, err)
+		}
+		return value, nil
+	}
+
+	name, fallback, hasFallback := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty variable reference ${%s}", ref)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok || (value == "" && hasFallback) {
+		if hasFallback {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loader reads a config file together with its includes and domains
+// directories. Files are merged depth-first in a fixed order: a file's own
+// entries, then its domains_dir files sorted by name, then its includes in
+// the listed order (glob matches sorted by name).
+type loader struct {
+	cfg       *Config
+	visited   map[string]bool   // absolute paths already loaded
+	setBy     map[string]string // top-level setting -> file:line that set it
+	domains   map[string]string // host:port -> file:line of its definition
+	notifiers map[string]string // notifier name -> file:line of its definition
+	errs      []error           // problems that don't stop loading
+}
+
+// newLoader creates a loader merging into cfg
+func newLoader(cfg *Config) *loader {
+	return &loader{
+		cfg:       cfg,
+		visited:   make(map[string]bool),
+		setBy:     make(map[string]string),
+		domains:   make(map[string]string),
+		notifiers: make(map[string]string),
+	}
+}
+
+// parseFile reads a YAML file and expands its values. It returns nil for an
+// empty file.
+func (l *loader) parseFile(path string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	if l.visited[abs] {
+		return nil, fmt.Errorf("%s: file is included more than once", path)
+	}
+	l.visited[abs] = true
+	l.cfg.Files = append(l.cfg.Files, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse YAML: %w", path, err)
+	}
+	if err := expandNode(&root, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("%s: failed to expand config: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	return root.Content[0], nil
+}
+
+// loadFile merges a config file and everything it includes
+func (l *loader) loadFile(path string) error {
+	doc, err := l.parseFile(path)
+	if err != nil || doc == nil {
+		return err
+	}
+	if doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s:%d: config must be a mapping", path, doc.Line)
+	}
+
+	// Sections that are not lists may only be set once across all files
+	part := Config{ReminderDays: l.cfg.ReminderDays, Connection: l.cfg.Connection, State: l.cfg.State, Log: l.cfg.Log}
+	if err := decodeStrict(path, doc, &part); err != nil {
+		l.errs = append(l.errs, err)
+	}
+	keys := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		keys[doc.Content[i].Value] = doc.Content[i+1]
+	}
+	for _, key := range []string{"reminder_days", "connection", "state", "log"} {
+		node, ok := keys[key]
+		if !ok {
+			continue
+		}
+		where := fmt.Sprintf("%s:%d", path, node.Line)
+		if prev, ok := l.setBy[key]; ok {
+			l.errs = append(l.errs, fmt.Errorf("%s: %s is already set at %s", where, key, prev))
+			continue
+		}
+		l.setBy[key] = where
+	}
+	l.cfg.ReminderDays, l.cfg.Connection, l.cfg.State, l.cfg.Log = part.ReminderDays, part.Connection, part.State, part.Log
+	if len(l.cfg.Files) == 1 {
+		l.cfg.Include, l.cfg.DomainsDir = part.Include, part.DomainsDir
+	}
+
+	// Domains merged in with << have no line of their own
+	var domainNodes []*yaml.Node
+	if node, ok := keys["domains"]; ok && resolveAlias(node).Kind == yaml.SequenceNode {
+		domainNodes = resolveAlias(node).Content
+	}
+	for i, d := range part.Domains {
+		d.Source = path
+		if i < len(domainNodes) {
+			d.Source = fmt.Sprintf("%s:%d", path, domainNodes[i].Line)
+		}
+		d.resolveFile(filepath.Dir(path))
+		l.addDomain(d)
+	}
+	for _, n := range part.Notifications {
+		n.Source, n.file = fmt.Sprintf("%s:%d", path, n.Settings.Line), path
+		if prev, ok := l.notifiers[n.Name]; ok {
+			l.errs = append(l.errs, fmt.Errorf("%s: duplicate notifier name %q (already defined at %s)", n.Source, n.Name, prev))
+			continue
+		}
+		l.notifiers[n.Name] = n.Source
+		l.cfg.Notifications = append(l.cfg.Notifications, n)
+	}
+	if node, ok := keys["routes"]; ok {
+		node = resolveAlias(node)
+		for i := 0; node.Kind == yaml.SequenceNode && i < len(part.Routes) && i < len(node.Content); i++ {
+			part.Routes[i].Source = fmt.Sprintf("%s:%d", path, node.Content[i].Line)
+		}
+	}
+	l.cfg.Routes = append(l.cfg.Routes, part.Routes...)
+
+	dir := filepath.Dir(path)
+	for _, src := range part.DomainSources {
+		src.Path = resolvePath(dir, src.Path)
+		l.cfg.DomainSources = append(l.cfg.DomainSources, src)
+	}
+	if part.DomainsDir != "" {
+		domainsDir := resolvePath(dir, part.DomainsDir)
+		l.cfg.dirs = append(l.cfg.dirs, domainsDir)
+		if err := l.loadDomainsDir(domainsDir); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range part.Include {
+		matches, err := filepath.Glob(resolvePath(dir, pattern))
+		if err != nil {
+			return fmt.Errorf("%s: invalid include pattern %q: %w", path, pattern, err)
+		}
+		// A plain path must exist, a pattern may match nothing
+		isGlob := strings.ContainsAny(pattern, "*?[")
+		if len(matches) == 0 && !isGlob {
+			return fmt.Errorf("%s: included file %s does not exist", path, pattern)
+		}
+		if isGlob {
+			l.cfg.dirs = append(l.cfg.dirs, filepath.Dir(resolvePath(dir, pattern)))
+		}
+		for _, match := range matches {
+			if err := l.loadFile(match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadDomainsDir loads every *.yaml and *.yml file of a directory in name
+// order. Hidden files are skipped.
+func (l *loader) loadDomainsDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read domains directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || strings.HasPrefix(name, ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		if err := l.loadDomainFile(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDomainFile loads a domains directory file. It is either a list of
+// domains or a mapping with defaults and domains:
+//
+//	defaults:
+//	  tags: [team-a]
+//	  notifiers: [team-a-slack]
+//	domains:
+//	  - host: a.example.com
+func (l *loader) loadDomainFile(path string) error {
+	doc, err := l.parseFile(path)
+	if err != nil || doc == nil {
+		return err
+	}
+
+	list := doc
+	var defaults DomainDefaults
+	if doc.Kind == yaml.MappingNode {
+		var file struct {
+			Defaults DomainDefaults `yaml:"defaults"`
+			Domains  yaml.Node      `yaml:"domains"`
+		}
+		if err := decodeStrict(path, doc, &file); err != nil {
+			l.errs = append(l.errs, err)
+		}
+		defaults, list = file.Defaults, resolveAlias(&file.Domains)
+	}
+	if list.Kind == 0 {
+		return nil
+	}
+	if list.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s:%d: domains must be a list", path, list.Line)
+	}
+
+	for _, item := range list.Content {
+		var d DomainConfig
+		if err := decodeStrict(path, item, &d); err != nil {
+			l.errs = append(l.errs, err)
+			continue
+		}
+		applyDomainDefaults(&d, defaults)
+		d.Source = fmt.Sprintf("%s:%d", path, item.Line)
+		d.resolveFile(filepath.Dir(path))
+		l.addDomain(d)
+	}
+	return nil
+}
+
+// addDomain appends a domain, rejecting endpoints and certificate files
+// that are already defined
+func (l *loader) addDomain(d DomainConfig) {
+	if d.Host != "" || d.File != "" {
+		endpoint := d.Endpoint()
+		if prev, ok := l.domains[endpoint]; ok {
+			l.errs = append(l.errs, fmt.Errorf("%s: duplicate domain %s (already defined at %s)", d.Source, endpoint, prev))
+			return
+		}
+		l.domains[endpoint] = d.Source
+	}
+	l.cfg.Domains = append(l.cfg.Domains, d)
+}
+
+// applyDomainDefaults fills in the per-file defaults of a domain. Default
+// tags are added to the domain's own and its labels take precedence.
+func applyDomainDefaults(d *DomainConfig, defaults DomainDefaults) {
+	if d.Group == "" {
+		d.Group = defaults.Group
+	}
+	if len(defaults.Tags) > 0 {
+		tags := append([]string{}, defaults.Tags...)
+		for _, tag := range d.Tags {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		d.Tags = tags
+	}
+	if len(defaults.Labels) > 0 {
+		labels := make(map[string]string, len(defaults.Labels)+len(d.Labels))
+		for key, value := range defaults.Labels {
+			labels[key] = value
+		}
+		for key, value := range d.Labels {
+			labels[key] = value
+		}
+		d.Labels = labels
+	}
+	if len(d.Notifiers) == 0 {
+		d.Notifiers = defaults.Notifiers
+	}
+	if len(d.ReminderDays) == 0 {
+		d.ReminderDays = defaults.ReminderDays
+	}
+	if d.CooldownHours == nil {
+		d.CooldownHours = defaults.CooldownHours
+	}
+}
+
+// resolveFile makes the certificate file of a domain relative to dir
+func (d *DomainConfig) resolveFile(dir string) {
+	if d.File != "" {
+		d.File = resolvePath(dir, d.File)
+	}
+}
+
+// resolvePath interprets path relative to dir unless it is absolute
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAlias returns the node an alias refers to, or node itself
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return node.Alias
+	}
+	return node
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NotifierConfig is a single named notifier definition. Type selects the
+// notifier implementation and Settings holds its type-specific keys
+// (e.g. SlackConfig for "slack").
+type NotifierConfig struct {
+	Name     string
+	Type     string
+	Enabled  bool
+	Settings yaml.Node
+
+	// Source is the file and line the notifier was defined at
+	Source string
+
+	file string // file the settings were read from, for error positions
+}
+
+// Decode decodes the notifier settings into the type-specific config struct.
+// Keys the struct has no field for are rejected.
+func (n NotifierConfig) Decode(v interface{}) error {
+	if n.Settings.Kind == 0 {
+		return nil
+	}
+	if err := decodeStrict(n.file, &n.Settings, v, "name", "type", "enabled"); err != nil {
+		return fmt.Errorf("invalid settings for notifier %q: %w", n.Name, err)
+	}
+	return nil
+}
+
+// UnmarshalYAML reads a notifier definition from the list layout:
+//
+//   - name: team-a
+//     type: slack
+//     webhook_url: ...
+//
+// Name defaults to the type and entries are enabled unless enabled: false.
+func (n *NotifierConfig) UnmarshalYAML(node *yaml.Node) error {
+	var header struct {
+		Name    string `yaml:"name"`
+		Type    string `yaml:"type"`
+		Enabled *bool  `yaml:"enabled"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return err
+	}
+	if header.Type == "" {
+		return fmt.Errorf("line %d: notifier is missing type", node.Line)
+	}
+
+	n.Name = header.Name
+	if n.Name == "" {
+		n.Name = header.Type
+	}
+	n.Type = header.Type
+	n.Enabled = header.Enabled == nil || *header.Enabled
+	n.Settings = *node
+	return nil
+}
+
+// NotificationsConfig holds all notifier definitions
+type NotificationsConfig []NotifierConfig
+
+// UnmarshalYAML accepts either a list of named notifier definitions or the
+// original layout with one block per notifier type:
+//
+//	slack:
+//	  enabled: true
+//	  webhook_url: ...
+//
+// In the latter form each block becomes a notifier named after its type.
+func (nc *NotificationsConfig) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var defs []NotifierConfig
+		if err := node.Decode(&defs); err != nil {
+			return err
+		}
+		*nc = defs
+		return nil
+
+	case yaml.MappingNode:
+		defs := make(NotificationsConfig, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			var header struct {
+				Enabled bool `yaml:"enabled"`
+			}
+			if err := value.Decode(&header); err != nil {
+				return fmt.Errorf("notifier %q: %w", key.Value, err)
+			}
+
+			defs = append(defs, NotifierConfig{
+				Name:     key.Value,
+				Type:     key.Value,
+				Enabled:  header.Enabled,
+				Settings: *value,
+			})
+		}
+		*nc = defs
+		return nil
+
+	default:
+		return fmt.Errorf("line %d: notifications must be a list or a mapping", node.Line)
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the domains of the source. Defaults are applied, but not the
+// global settings (see Config.RefreshDomains).
+func (s DomainSource) Load() ([]DomainConfig, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain source: %w", err)
+	}
+
+	var domains []DomainConfig
+	switch s.Type {
+	case "csv":
+		domains, err = s.parseCSV(data)
+	case "text":
+		domains, err = s.parseText(data)
+	case "json":
+		domains, err = s.parseJSON(data)
+	default:
+		return nil, fmt.Errorf("%s: unknown domain source type %q (use csv, text or json)", s.Path, s.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range domains {
+		applyDomainDefaults(&domains[i], s.Defaults)
+	}
+	return domains, nil
+}
+
+// parseCSV reads domains from CSV rows using the column mapping
+func (s DomainSource) parseCSV(data []byte) ([]DomainConfig, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	applyDefault(&s, "Delimiter")
+	applyDefault(&s, "TagSeparator")
+	delimiter, size := utf8.DecodeRuneInString(s.Delimiter)
+	if size != len(s.Delimiter) {
+		return nil, fmt.Errorf("%s: delimiter must be a single character", s.Path)
+	}
+	reader.Comma = delimiter
+
+	var header []string
+	if !s.NoHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Path, err)
+		}
+		header = record
+	}
+
+	hostCol, err := s.columnIndex(header, s.Columns.Host, "host")
+	if err != nil {
+		return nil, err
+	}
+	if hostCol < 0 {
+		if !s.NoHeader {
+			return nil, fmt.Errorf("%s: column %q not found in header", s.Path, "host")
+		}
+		hostCol = 0 // the first column by default
+	}
+	portCol, err := s.columnIndex(header, s.Columns.Port, "port")
+	if err != nil {
+		return nil, err
+	}
+	nameCol, err := s.columnIndex(header, s.Columns.Name, "name")
+	if err != nil {
+		return nil, err
+	}
+	tagsCol, err := s.columnIndex(header, s.Columns.Tags, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var domains []DomainConfig
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Path, err)
+		}
+		line, _ := reader.FieldPos(0)
+		where := fmt.Sprintf("%s:%d", s.Path, line)
+
+		hostField := field(record, hostCol)
+		if hostField == "" {
+			continue
+		}
+		host, port, err := parseEndpoint(hostField)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		if value := field(record, portCol); value != "" {
+			port, err = parsePort(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", where, err)
+			}
+		}
+
+		d := DomainConfig{Host: host, Port: port, Name: field(record, nameCol), Source: where}
+		for _, tag := range strings.Split(field(record, tagsCol), s.TagSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				d.Tags = append(d.Tags, tag)
+			}
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// columnIndex returns the 0-based index of a CSV column, or -1 when it is
+// absent. Configured columns must exist, the default names are optional.
+func (s DomainSource) columnIndex(header []string, configured, fallback string) (int, error) {
+	if s.NoHeader {
+		if configured == "" {
+			return -1, nil
+		}
+		n, err := strconv.Atoi(configured)
+		if err != nil || n < 1 {
+			return -1, fmt.Errorf("%s: column %q must be a number without a header", s.Path, configured)
+		}
+		return n - 1, nil
+	}
+
+	name := configured
+	if name == "" {
+		name = fallback
+	}
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return i, nil
+		}
+	}
+	if configured != "" {
+		return -1, fmt.Errorf("%s: column %q not found in header", s.Path, configured)
+	}
+	return -1, nil
+}
+
+// parseText reads one host[:port] or URL per line. Lines in hosts file
+// format ("<address> <name> [aliases...]") contribute their names, and
+// everything after "#" is a comment.
+func (s DomainSource) parseText(data []byte) ([]DomainConfig, error) {
+	var domains []DomainConfig
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		}
+
+		where := fmt.Sprintf("%s:%d", s.Path, line)
+		for _, f := range fields {
+			host, port, err := parseEndpoint(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", where, err)
+			}
+			domains = append(domains, DomainConfig{Host: host, Port: port, Source: where})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return domains, nil
+}
+
+// parseJSON reads an array whose items are host[:port] strings or objects
+// with the same keys as domains in the config file
+func (s DomainSource) parseJSON(data []byte) ([]DomainConfig, error) {
+	// JSON is valid YAML, which gives line numbers and the config field names
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON: %w", s.Path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	list := root.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s: expected a JSON array", s.Path)
+	}
+
+	var domains []DomainConfig
+	for _, item := range list.Content {
+		where := fmt.Sprintf("%s:%d", s.Path, item.Line)
+		var d DomainConfig
+		switch item.Kind {
+		case yaml.ScalarNode:
+			host, port, err := parseEndpoint(item.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", where, err)
+			}
+			d = DomainConfig{Host: host, Port: port}
+		case yaml.MappingNode:
+			if err := decodeStrict(s.Path, item, &d); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%s: expected a string or an object", where)
+		}
+		d.Source = where
+		d.resolveFile(filepath.Dir(s.Path))
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// parseEndpoint splits "host", "host:port", "[v6]:port" or a URL into host
+// and port. The port is 0 when not given.
+func parseEndpoint(s string) (string, int, error) {
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid URL %q: %w", s, err)
+		}
+		if u.Hostname() == "" {
+			return "", 0, fmt.Errorf("URL %q has no host", s)
+		}
+		if u.Port() == "" {
+			return u.Hostname(), 0, nil
+		}
+		port, err := parsePort(u.Port())
+		return u.Hostname(), port, err
+	}
+
+	host, portText, err := net.SplitHostPort(s)
+	if err != nil {
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) && (addrErr.Err == "missing port in address" || addrErr.Err == "too many colons in address") {
+			// A bare host name or IPv6 address
+			return strings.Trim(s, "[]"), 0, nil
+		}
+		return "", 0, fmt.Errorf("invalid endpoint %q: %w", s, err)
+	}
+	if host == "" {
+		return "", 0, fmt.Errorf("invalid endpoint %q: missing host", s)
+	}
+	port, err := parsePort(portText)
+	return host, port, err
+}
+
+// parsePort parses a TCP port number
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
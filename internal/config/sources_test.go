@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// endpoints returns host:port for each domain, with port 0 when none was
+// given
+func endpoints(domains []DomainConfig) []string {
+	var list []string
+	for _, d := range domains {
+		list = append(list, fmt.Sprintf("%s:%d", d.Host, d.Port))
+	}
+	return list
+}
+
+// loadSource writes content to a file of the source's type and loads it
+func loadSource(t *testing.T, s DomainSource, content string) ([]DomainConfig, error) {
+	t.Helper()
+	dir := t.TempDir()
+	s.Path = filepath.Join(dir, "endpoints."+s.Type)
+	writeFiles(t, dir, map[string]string{"endpoints." + s.Type: content})
+	return s.Load()
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		in   string
+		host string
+		port int
+	}{
+		{"example.com", "example.com", 0},
+		{"example.com:8443", "example.com", 8443},
+		{"[::1]:443", "::1", 443},
+		{"::1", "::1", 0},
+		{"[2001:db8::1]", "2001:db8::1", 0},
+		{"https://example.com/path", "example.com", 0},
+		{"https://example.com:8443", "example.com", 8443},
+		{"https://[::1]:9443/", "::1", 9443},
+	}
+	for _, tt := range tests {
+		host, port, err := parseEndpoint(tt.in)
+		if err != nil {
+			t.Errorf("parseEndpoint(%q): %v", tt.in, err)
+			continue
+		}
+		if host != tt.host || port != tt.port {
+			t.Errorf("parseEndpoint(%q) = %q, %d; want %q, %d", tt.in, host, port, tt.host, tt.port)
+		}
+	}
+
+	for _, in := range []string{"example.com:0", "example.com:65536", "example.com:https", ":443", "https://:443"} {
+		if _, _, err := parseEndpoint(in); err == nil {
+			t.Errorf("parseEndpoint(%q): expected an error", in)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	domains, err := loadSource(t, DomainSource{Type: "csv", TagSeparator: ";"}, `# inventory export
+Name, Host, Port, Tags
+Web, www.example.com, , web;public
+
+# spare
+API, api.example.com:8443, , api
+Admin, [::1]:9443, ,
+Override, db.example.com:5432, 6432,
+, , ,
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"www.example.com:0", "api.example.com:8443", "::1:9443", "db.example.com:6432"}
+	if got := endpoints(domains); !reflect.DeepEqual(got, want) {
+		t.Fatalf("endpoints = %v, want %v", got, want)
+	}
+	if domains[0].Name != "Web" || !reflect.DeepEqual(domains[0].Tags, []string{"web", "public"}) {
+		t.Errorf("first domain = %+v", domains[0])
+	}
+	if !strings.HasSuffix(domains[1].Source, "endpoints.csv:6") {
+		t.Errorf("source = %q, want the line of the row", domains[1].Source)
+	}
+}
+
+func TestParseCSVWithoutHeader(t *testing.T) {
+	domains, err := loadSource(t, DomainSource{Type: "csv", NoHeader: true, Delimiter: ";", Columns: CSVColumns{Host: "2", Name: "1"}}, `Web;www.example.com
+API;api.example.com:8443
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"www.example.com:0", "api.example.com:8443"}
+	if got := endpoints(domains); !reflect.DeepEqual(got, want) {
+		t.Fatalf("endpoints = %v, want %v", got, want)
+	}
+	if domains[1].Name != "API" {
+		t.Errorf("name = %q, want API", domains[1].Name)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  DomainSource
+		content string
+		want    string
+	}{
+		{"missing host column", DomainSource{Type: "csv"}, "name,port\nweb,443\n", `column "host" not found in header`},
+		{"missing configured column", DomainSource{Type: "csv", Columns: CSVColumns{Tags: "labels"}}, "host\nexample.com\n", `column "labels" not found in header`},
+		{"named column without header", DomainSource{Type: "csv", NoHeader: true, Columns: CSVColumns{Host: "host"}}, "example.com\n", "must be a number without a header"},
+		{"invalid port", DomainSource{Type: "csv"}, "host,port\nexample.com,443\nexample.org,http\n", `endpoints.csv:3: invalid port "http"`},
+		{"long delimiter", DomainSource{Type: "csv", Delimiter: ";;"}, "host\n", "delimiter must be a single character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadSource(t, tt.source, tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	domains, err := loadSource(t, DomainSource{Type: "text"}, `# production
+www.example.com
+api.example.com:8443 # public API
+
+10.0.0.1 web1.example.com web1
+[::1]:9443, https://status.example.com
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"www.example.com:0", "api.example.com:8443", "web1.example.com:0", "web1:0", "::1:9443", "status.example.com:0"}
+	if got := endpoints(domains); !reflect.DeepEqual(got, want) {
+		t.Fatalf("endpoints = %v, want %v", got, want)
+	}
+	if !strings.HasSuffix(domains[2].Source, "endpoints.text:5") {
+		t.Errorf("source = %q, want the line of the host", domains[2].Source)
+	}
+
+	_, err = loadSource(t, DomainSource{Type: "text"}, "www.example.com\nexample.org:99999\n")
+	if err == nil || !strings.Contains(err.Error(), `endpoints.text:2: invalid port "99999"`) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	domains, err := loadSource(t, DomainSource{Type: "json", Defaults: DomainDefaults{Group: "infra"}}, `[
+  "www.example.com",
+  "[::1]:9443",
+  {"host": "api.example.com", "port": 8443, "tags": ["api"], "group": "platform"}
+]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"www.example.com:0", "::1:9443", "api.example.com:8443"}
+	if got := endpoints(domains); !reflect.DeepEqual(got, want) {
+		t.Fatalf("endpoints = %v, want %v", got, want)
+	}
+	if domains[0].Group != "infra" || domains[2].Group != "platform" {
+		t.Errorf("groups = %q %q, want the default and the domain's own", domains[0].Group, domains[2].Group)
+	}
+	if !strings.HasSuffix(domains[2].Source, "endpoints.json:4") {
+		t.Errorf("source = %q, want the line of the object", domains[2].Source)
+	}
+
+	tests := map[string]string{
+		`{"host": "example.com"}`:   "expected a JSON array",
+		`[["example.com"]]`:         "expected a string or an object",
+		`[{"hots": "example.com"}]`: `unknown field "hots"`,
+	}
+	for content, want := range tests {
+		if _, err := loadSource(t, DomainSource{Type: "json"}, content); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestRefreshDomains(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `domains:
+  - host: www.example.com
+domain_sources:
+  - type: text
+    path: endpoints.txt
+`,
+		"endpoints.txt": "api.example.com\nwww.example.com\n",
+	})
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Endpoints defined in the config are not added twice, and the global
+	// port default is applied
+	want := []string{"www.example.com:443", "api.example.com:443"}
+	if got := endpoints(cfg.Domains); !reflect.DeepEqual(got, want) {
+		t.Fatalf("endpoints = %v, want %v", got, want)
+	}
+
+	// Each run reads the source again
+	writeFiles(t, dir, map[string]string{"endpoints.txt": "status.example.com:8443\n"})
+	if err := cfg.RefreshDomains(); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"www.example.com:443", "status.example.com:8443"}
+	if got := endpoints(cfg.Domains); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints after refreshing = %v, want %v", got, want)
+	}
+
+	writeFiles(t, dir, map[string]string{"endpoints.txt": "example.com:0\n"})
+	if err := cfg.RefreshDomains(); err == nil {
+		t.Error("expected an error for an invalid port")
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	nodeType        = reflect.TypeOf(yaml.Node{})
+)
+
+// decodeStrict decodes node into v like node.Decode, but also rejects keys
+// that have no matching field, like a decoder with KnownFields set. Keys in
+// extra are accepted at the top level. All problems are reported together,
+// each prefixed with file:line.
+func decodeStrict(file string, node *yaml.Node, v interface{}, extra ...string) error {
+	var errs []error
+	if err := node.Decode(v); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			msg := err.Error()
+			if !strings.HasPrefix(msg, "line ") {
+				msg = location(file, node.Line) + ": " + msg
+			}
+			return errors.New(locateMessage(file, msg))
+		}
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, errors.New(locateMessage(file, msg)))
+		}
+	}
+	checkFields(file, node, reflect.TypeOf(v), extra, &errs)
+	return errors.Join(errs...)
+}
+
+// checkFields reports the keys of node that type t has no field for
+func checkFields(file string, node *yaml.Node, t reflect.Type, extra []string, errs *[]error) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types with their own decoding check their keys themselves
+	if t == nodeType || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		structFields(t, fields)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				checkFields(file, value, t, extra, errs)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				if !containsString(extra, key.Value) {
+					*errs = append(*errs, fmt.Errorf("%s: unknown field %q", location(file, key.Line), key.Value))
+				}
+				continue
+			}
+			checkFields(file, value, field, nil, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			checkFields(file, item, t.Elem(), nil, errs)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkFields(file, node.Content[i+1], t.Elem(), nil, errs)
+		}
+	}
+}
+
+// structFields collects the YAML keys of a struct and their types, including
+// those of inlined structs
+func structFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			structFields(field.Type, fields)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+}
+
+// location formats a position as file:line, or "line N" without a file
+func location(file string, line int) string {
+	if file == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// locateMessage rewrites a "line N: ..." message of the YAML decoder to
+// start with file:line
+func locateMessage(file, msg string) string {
+	rest, ok := strings.CutPrefix(msg, "line ")
+	if !ok {
+		return msg
+	}
+	number, rest, ok := strings.Cut(rest, ": ")
+	line, err := strconv.Atoi(number)
+	if !ok || err != nil {
+		return msg
+	}
+	return location(file, line) + ": " + rest
+}
@@ -1,45 +1,235 @@
 package config
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // DomainConfig represents a single domain to monitor
 type DomainConfig struct {
-	Host                 string `yaml:"host"`
-	Port                 int    `yaml:"port"`
-	Name                 string `yaml:"name,omitempty"`
-	InsecureSkipVerify   bool   `yaml:"insecure_skip_verify,omitempty"`
+	Host               string `yaml:"host"`                           // host name or IP address to connect to
+	Port               int    `yaml:"port" default:"443"`             // defaults to 443
+	Name               string `yaml:"name,omitempty"`                 // display name, defaults to the host
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"` // don't verify the certificate chain
+
+	// Certificates stored on disk are checked by setting File instead of Host
+	File     string `yaml:"file,omitempty"`     // PEM, DER, PKCS#12, JKS or JCEKS file, glob pattern or directory, relative to the config file
+	Password string `yaml:"password,omitempty"` // password of PKCS#12 files and Java keystores
+
+	// Connection overrides the global connection settings field by field
+	Connection ConnectionConfig `yaml:"connection,omitempty"`
+
+	// Per-domain overrides; the global values are used when these are unset
+	ReminderDays  []int    `yaml:"reminder_days,omitempty"`  // days before expiry to notify at
+	CooldownHours *int     `yaml:"cooldown_hours,omitempty"` // hours between repeated notifications, 0 repeats on every run
+	Notifiers     []string `yaml:"notifiers,omitempty"`      // names of notifiers to route to, empty means all
+
+	// Grouping used by routing rules and passed on to notifiers
+	Group  string            `yaml:"group,omitempty"`  // e.g. a team or environment
+	Tags   []string          `yaml:"tags,omitempty"`   // free-form tags
+	Labels map[string]string `yaml:"labels,omitempty"` // free-form key/value pairs
+
+	// Source is the file and line the domain was defined at
+	Source string `yaml:"-"`
+	// Alias is the keystore entry a check result is about
+	Alias string `yaml:"-"`
+}
+
+// Key identifies the domain in the state file: its host, or the path of its
+// certificate file followed by #alias for keystore entries
+func (d DomainConfig) Key() string {
+	if d.File != "" {
+		return d.fileKey()
+	}
+	return d.Host
+}
+
+// Endpoint returns host:port, or the path of the certificate file followed
+// by #alias for keystore entries
+func (d DomainConfig) Endpoint() string {
+	if d.File != "" {
+		return d.fileKey()
+	}
+	port := d.Port
+	if port == 0 {
+		port = 443
+	}
+	return fmt.Sprintf("%s:%d", d.Host, port)
+}
+
+func (d DomainConfig) fileKey() string {
+	if d.Alias != "" {
+		return d.File + "#" + d.Alias
+	}
+	return d.File
 }
 
-// SlackConfig holds Slack webhook configuration
+// DisplayName returns the configured name, falling back to the host or the
+// certificate file
+func (d DomainConfig) DisplayName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.Key()
+}
+
+// ConnectionConfig holds the network settings of certificate checks. Unset
+// fields of a domain's settings are taken from the global ones.
+type ConnectionConfig struct {
+	DialTimeout      int    `yaml:"dial_timeout,omitempty" default:"10"`      // seconds, defaults to 10
+	HandshakeTimeout int    `yaml:"handshake_timeout,omitempty" default:"10"` // seconds, defaults to 10
+	Retries          int    `yaml:"retries,omitempty"`                        // extra attempts after a network error
+	RetryBackoff     int    `yaml:"retry_backoff,omitempty" default:"1"`      // seconds before the first retry, doubled for each further one, defaults to 1
+	SourceAddress    string `yaml:"source_address,omitempty"`                 // local IP address to connect from
+	Proxy            string `yaml:"proxy,omitempty"`                          // URL of an http, https, socks5 or socks5h proxy, may include user:password; "none" connects directly, unset uses HTTPS_PROXY and NO_PROXY
+
+	// NotifierTimeout limits notifier HTTP requests. It can only be set
+	// globally.
+	NotifierTimeout int `yaml:"notifier_timeout,omitempty" default:"10"` // seconds, defaults to 10
+}
+
+// DomainSource loads domains from an inventory file on every run
+type DomainSource struct {
+	Type string `yaml:"type" enum:"csv,text,json"` // csv, text or json
+	Path string `yaml:"path"`                      // relative to the config file that declares it
+
+	// CSV settings
+	Columns      CSVColumns `yaml:"columns,omitempty"`
+	Delimiter    string     `yaml:"delimiter,omitempty" default:","`     // defaults to ","
+	NoHeader     bool       `yaml:"no_header,omitempty"`                 // columns are 1-based numbers
+	TagSeparator string     `yaml:"tag_separator,omitempty" default:","` // defaults to ","
+
+	// Defaults are applied to every loaded domain
+	Defaults DomainDefaults `yaml:"defaults,omitempty"`
+}
+
+// CSVColumns maps domain fields to CSV columns, by header name or, with
+// no_header, by 1-based column number
+type CSVColumns struct {
+	Host string `yaml:"host,omitempty" default:"host"` // defaults to "host"
+	Port string `yaml:"port,omitempty" default:"port"` // defaults to "port"
+	Name string `yaml:"name,omitempty" default:"name"` // defaults to "name"
+	Tags string `yaml:"tags,omitempty" default:"tags"` // defaults to "tags"
+}
+
+// DomainDefaults are applied to every domain of a domains directory file
+type DomainDefaults struct {
+	Group         string            `yaml:"group,omitempty"`
+	Tags          []string          `yaml:"tags,omitempty"`      // added to the domain's tags
+	Labels        map[string]string `yaml:"labels,omitempty"`    // domain labels take precedence
+	Notifiers     []string          `yaml:"notifiers,omitempty"` // used when the domain has none
+	ReminderDays  []int             `yaml:"reminder_days,omitempty"`
+	CooldownHours *int              `yaml:"cooldown_hours,omitempty"`
+}
+
+// SlackConfig holds Slack webhook or bot configuration. Setting BotToken
+// switches from the incoming webhook to chat.postMessage, which threads
+// follow-up alerts for an endpoint under the first message.
 type SlackConfig struct {
 	Enabled    bool   `yaml:"enabled"`
 	WebhookURL string `yaml:"webhook_url"`
 	Channel    string `yaml:"channel,omitempty"`
 	Username   string `yaml:"username,omitempty"`
 	IconEmoji  string `yaml:"icon_emoji,omitempty"`
+	BlockKit   bool   `yaml:"block_kit,omitempty"`                               // send Block Kit layouts instead of plain text
+	BotToken   string `yaml:"bot_token,omitempty"`                               // xoxb- token with chat:write
+	APIURL     string `yaml:"api_url,omitempty" default:"https://slack.com/api"` // defaults to https://slack.com/api
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
 }
 
 // EmailConfig holds SMTP email configuration
 type EmailConfig struct {
-	Enabled   bool   `yaml:"enabled"`
-	SMTPHost  string `yaml:"smtp_host"`
-	SMTPPort  int    `yaml:"smtp_port"`
-	Username  string `yaml:"username"`
-	Password  string `yaml:"password"`
-	From      string `yaml:"from"`
-	To        string `yaml:"to"`
-	UseTLS    bool   `yaml:"use_tls"`
+	Enabled    bool        `yaml:"enabled"`
+	SMTPHost   string      `yaml:"smtp_host"`
+	SMTPPort   int         `yaml:"smtp_port"`
+	Username   string      `yaml:"username"`
+	Password   string      `yaml:"password"`
+	From       string      `yaml:"from"`
+	To         AddressList `yaml:"to"`
+	Cc         AddressList `yaml:"cc,omitempty"`
+	Bcc        AddressList `yaml:"bcc,omitempty"`
+	UseTLS     bool        `yaml:"use_tls"`                                         // deprecated, same as tls: starttls
+	TLS        string      `yaml:"tls,omitempty" enum:"none,starttls,tls"`          // none, starttls or tls (implicit, usually port 465); unset uses STARTTLS when offered
+	Auth       string      `yaml:"auth,omitempty" enum:"none,plain,login,cram-md5"` // none, plain, login or cram-md5; plain if a username is set
+	SkipVerify bool        `yaml:"tls_skip_verify,omitempty"`                       // don't verify the server certificate
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// AddressList is a list of email addresses. It accepts a YAML list or a
+// single comma-separated string.
+type AddressList []string
+
+// UnmarshalYAML accepts either a list or a comma-separated string
+func (a *AddressList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*a = nil
+		for _, addr := range strings.Split(node.Value, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				*a = append(*a, addr)
+			}
+		}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
 }
 
 // WebhookConfig holds generic webhook configuration
 type WebhookConfig struct {
-	Enabled  bool              `yaml:"enabled"`
-	URL      string            `yaml:"url"`
-	Method   string            `yaml:"method"`
-	Headers  map[string]string `yaml:"headers"`
-	BodyTemplate string        `yaml:"body_template"`
+	Enabled      bool              `yaml:"enabled"`
+	URL          string            `yaml:"url"`
+	Method       string            `yaml:"method" default:"POST"` // defaults to POST
+	Headers      map[string]string `yaml:"headers"`               // extra request headers
+	BodyTemplate string            `yaml:"body_template"`         // same as templates.body
+
+	// Signing adds an HMAC-SHA256 signature of the timestamp and body
+	Signing WebhookSigningConfig `yaml:"signing,omitempty"`
+	// BasicAuth and OAuth2 are mutually exclusive
+	BasicAuth *WebhookBasicAuth    `yaml:"basic_auth,omitempty"`
+	OAuth2    *WebhookOAuth2Config `yaml:"oauth2,omitempty"`
+	// TLS configures client certificates (mTLS) and server verification
+	TLS ClientTLSConfig `yaml:"tls,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// WebhookSigningConfig holds the HMAC signing settings of a webhook
+type WebhookSigningConfig struct {
+	Secret          string `yaml:"secret"`
+	SignatureHeader string `yaml:"signature_header" default:"X-Signature-256"`       // default X-Signature-256
+	TimestampHeader string `yaml:"timestamp_header" default:"X-Signature-Timestamp"` // default X-Signature-Timestamp
+}
+
+// WebhookBasicAuth holds HTTP basic auth credentials
+type WebhookBasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// WebhookOAuth2Config holds OAuth2 client credentials grant settings
+type WebhookOAuth2Config struct {
+	TokenURL       string            `yaml:"token_url"`
+	ClientID       string            `yaml:"client_id"`
+	ClientSecret   string            `yaml:"client_secret"`
+	Scopes         []string          `yaml:"scopes"`
+	EndpointParams map[string]string `yaml:"endpoint_params"` // e.g. audience
+}
+
+// ClientTLSConfig holds the TLS settings of a notifier client
+type ClientTLSConfig struct {
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	CAFile             string `yaml:"ca_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
 }
 
 // DiscordConfig holds Discord webhook configuration
@@ -48,48 +238,272 @@ type DiscordConfig struct {
 	WebhookURL string `yaml:"webhook_url"`
 	Username   string `yaml:"username,omitempty"`
 	AvatarURL  string `yaml:"avatar_url,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// TeamsConfig holds Microsoft Teams webhook configuration
+type TeamsConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhook_url"` // incoming webhook or Workflows URL
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// PagerDutyConfig holds PagerDuty Events API v2 configuration
+type PagerDutyConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	RoutingKey string `yaml:"routing_key"`
+	URL        string `yaml:"url,omitempty" default:"https://events.pagerduty.com/v2/enqueue"` // defaults to the public Events API endpoint, https://events.pagerduty.com/v2/enqueue
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// OpsgenieConfig holds Opsgenie Alert API configuration
+type OpsgenieConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	APIKey  string   `yaml:"api_key"`
+	Region  string   `yaml:"region,omitempty" default:"us" enum:"us,eu" ignorecase:"true"` // "us" (default) or "eu"
+	BaseURL string   `yaml:"base_url,omitempty"`                                           // overrides the region URL
+	Tags    []string `yaml:"tags,omitempty"`                                               // added to the domain tags
+	Team    string   `yaml:"team,omitempty"`                                               // responder team name
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// TelegramConfig holds Telegram Bot API configuration
+type TelegramConfig struct {
+	Enabled             bool           `yaml:"enabled"`
+	BotToken            string         `yaml:"bot_token"`
+	Chats               []TelegramChat `yaml:"chats"`
+	ParseMode           string         `yaml:"parse_mode,omitempty" default:"MarkdownV2" enum:"MarkdownV2,HTML"` // MarkdownV2 (default) or HTML
+	DisableNotification bool           `yaml:"disable_notification,omitempty"`
+	APIURL              string         `yaml:"api_url,omitempty" default:"https://api.telegram.org"` // defaults to https://api.telegram.org
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// TelegramChat is a chat (and optionally a forum topic) to post to.
+// A plain scalar is accepted as shorthand for the chat ID.
+type TelegramChat struct {
+	ID       string `yaml:"id"` // numeric ID or @channelusername
+	ThreadID int    `yaml:"thread_id,omitempty"`
+}
+
+// UnmarshalYAML accepts either a chat ID or a mapping with id and thread_id
+func (c *TelegramChat) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.ID = node.Value
+		return nil
+	}
+	type plain TelegramChat
+	return node.Decode((*plain)(c))
+}
+
+// MatrixConfig holds Matrix client-server API configuration
+type MatrixConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	HomeserverURL string `yaml:"homeserver_url"`
+	AccessToken   string `yaml:"access_token"`
+	RoomID        string `yaml:"room_id"`                                                     // e.g. !abc123:example.org
+	MsgType       string `yaml:"msgtype,omitempty" default:"m.notice" enum:"m.notice,m.text"` // message type, m.notice (default) or m.text
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// MattermostConfig holds Mattermost incoming webhook configuration
+type MattermostConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhook_url"`
+	Channel    string `yaml:"channel,omitempty"`
+	Username   string `yaml:"username,omitempty"`
+	IconURL    string `yaml:"icon_url,omitempty"`
+	IconEmoji  string `yaml:"icon_emoji,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// RocketChatConfig holds Rocket.Chat incoming webhook configuration
+type RocketChatConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhook_url"`
+	Channel    string `yaml:"channel,omitempty"`
+	Alias      string `yaml:"alias,omitempty"`
+	Emoji      string `yaml:"emoji,omitempty"`
+	Avatar     string `yaml:"avatar,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// GoogleChatConfig holds Google Chat space webhook configuration
+type GoogleChatConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhook_url"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// NtfyConfig holds ntfy publishing configuration
+type NtfyConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	ServerURL string `yaml:"server_url,omitempty" default:"https://ntfy.sh"` // defaults to https://ntfy.sh
+	Topic     string `yaml:"topic"`
+	Token     string `yaml:"token,omitempty"` // access token, or use username/password
+	Username  string `yaml:"username,omitempty"`
+	Password  string `yaml:"password,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// GotifyConfig holds Gotify server configuration
+type GotifyConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	ServerURL string `yaml:"server_url"`
+	AppToken  string `yaml:"app_token"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// PushoverConfig holds Pushover API configuration
+type PushoverConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	APIToken string `yaml:"api_token"`
+	UserKey  string `yaml:"user_key"`
+	Device   string `yaml:"device,omitempty"`
+	Sound    string `yaml:"sound,omitempty"`
+	APIURL   string `yaml:"api_url,omitempty" default:"https://api.pushover.net"` // defaults to https://api.pushover.net
+
+	// Emergency priority (used for expired certificates) repeats the alert
+	// every Retry seconds until acknowledged or Expire seconds have passed
+	Retry  int `yaml:"retry,omitempty" default:"300"`   // seconds, at least 30, defaults to 300
+	Expire int `yaml:"expire,omitempty" default:"3600"` // seconds, defaults to 3600
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// SyslogConfig holds RFC 5424 syslog configuration
+type SyslogConfig struct {
+	Enabled  bool            `yaml:"enabled"`
+	Network  string          `yaml:"network" default:"unix" enum:"udp,tcp,tls,unix"` // udp, tcp, tls or unix (default)
+	Address  string          `yaml:"address"`                                        // host:port, or a socket path for unix (default /dev/log)
+	Facility string          `yaml:"facility,omitempty" default:"daemon"`            // defaults to daemon
+	AppName  string          `yaml:"app_name,omitempty" default:"ssl-cert-monitor"`  // defaults to ssl-cert-monitor
+	Hostname string          `yaml:"hostname,omitempty"`                             // defaults to the local host name
+	TLS      ClientTLSConfig `yaml:"tls,omitempty"`
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
 }
 
-// NotificationsConfig holds all notification channel configurations
-type NotificationsConfig struct {
-	Slack   SlackConfig   `yaml:"slack"`
-	Email   EmailConfig   `yaml:"email"`
-	Webhook WebhookConfig `yaml:"webhook"`
-	Discord DiscordConfig `yaml:"discord"`
+// ExecConfig holds the settings of a local command notifier
+type ExecConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	Command       []string          `yaml:"command"`                              // program and arguments, not run through a shell
+	Env           map[string]string `yaml:"env,omitempty"`                        // added to the inherited environment
+	WorkingDir    string            `yaml:"working_dir,omitempty"`                // defaults to the current directory
+	Timeout       int               `yaml:"timeout,omitempty" default:"30"`       // seconds, defaults to 30
+	MaxConcurrent int               `yaml:"max_concurrent,omitempty" default:"1"` // parallel runs, defaults to 1
+	LockFile      string            `yaml:"lock_file,omitempty"`                  // held while a command runs, so separate processes take turns
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// JournaldConfig holds systemd journal configuration
+type JournaldConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	SocketPath string `yaml:"socket_path,omitempty" default:"/run/systemd/journal/socket"` // defaults to /run/systemd/journal/socket
+	Identifier string `yaml:"identifier,omitempty" default:"ssl-cert-monitor"`             // SYSLOG_IDENTIFIER, defaults to ssl-cert-monitor
+
+	Templates TemplateConfig `yaml:"templates,omitempty"`
+}
+
+// TemplateConfig overrides a notifier's message wording with Go templates,
+// given inline or loaded from a file. Empty templates keep the default text.
+type TemplateConfig struct {
+	Title        string `yaml:"title,omitempty"`
+	TitleFile    string `yaml:"title_file,omitempty"`
+	Body         string `yaml:"body,omitempty"`
+	BodyFile     string `yaml:"body_file,omitempty"`
+	Subject      string `yaml:"subject,omitempty"` // email only
+	SubjectFile  string `yaml:"subject_file,omitempty"`
+	HTMLBody     string `yaml:"html_body,omitempty"` // email only, rendered with html/template
+	HTMLBodyFile string `yaml:"html_body_file,omitempty"`
+}
+
+// RouteConfig is a routing rule selecting the notifiers for matching
+// notifications. Rules are evaluated in order and the first match wins
+// unless Continue is set.
+type RouteConfig struct {
+	Name      string     `yaml:"name,omitempty"`     // used in log and error messages
+	Match     RouteMatch `yaml:"match"`              // conditions a notification must meet
+	Notifiers []string   `yaml:"notifiers"`          // empty drops matching notifications
+	Continue  bool       `yaml:"continue,omitempty"` // evaluate later rules after a match
+
+	// Source is the file and line the route was defined at
+	Source string `yaml:"-"`
+}
+
+// RouteMatch holds the conditions of a routing rule. All conditions that
+// are set must match; an empty match matches everything.
+type RouteMatch struct {
+	Groups   []string          `yaml:"groups,omitempty"`   // domain group is one of these
+	Tags     []string          `yaml:"tags,omitempty"`     // domain has all of these tags
+	Labels   map[string]string `yaml:"labels,omitempty"`   // domain labels have these values
+	Hosts    []string          `yaml:"hosts,omitempty"`    // host matches one of these globs, * also matches dots
+	Severity []string          `yaml:"severity,omitempty"` // severity is one of these
+	MinDays  *float64          `yaml:"min_days,omitempty"` // days remaining >= MinDays
+	MaxDays  *float64          `yaml:"max_days,omitempty"` // days remaining <= MaxDays
 }
 
 // StateConfig holds state persistence configuration
 type StateConfig struct {
-	File          string `yaml:"file"`
-	CooldownHours int    `yaml:"cooldown_hours"`
+	File          string `yaml:"file"`                        // JSON file remembering sent notifications
+	CooldownHours int    `yaml:"cooldown_hours" default:"24"` // hours between repeated notifications, defaults to 24
 }
 
 // LogConfig holds logging configuration
 type LogConfig struct {
-	Level string `yaml:"level"`
-	File  string `yaml:"file,omitempty"`
+	Level string `yaml:"level" default:"info" enum:"debug,info,warn,error"` // debug, info (default), warn or error
+	File  string `yaml:"file,omitempty"`                                    // log to this file instead of stdout
 }
 
 // Config is the root configuration structure
 type Config struct {
-	Domains       []DomainConfig      `yaml:"domains"`
-	ReminderDays  []int               `yaml:"reminder_days"`
-	Notifications NotificationsConfig `yaml:"notifications"`
+	// Include lists further config files (glob patterns, relative to the
+	// including file) whose domains, notifiers and routes are appended
+	Include []string `yaml:"include,omitempty"`
+	// DomainsDir is a directory of *.yaml files contributing domains
+	DomainsDir string `yaml:"domains_dir,omitempty"`
+
+	Domains       []DomainConfig      `yaml:"domains"`                  // endpoints to monitor
+	DomainSources []DomainSource      `yaml:"domain_sources,omitempty"` // inventory files read on every run
+	ReminderDays  []int               `yaml:"reminder_days"`            // days before expiry to notify at
+	Notifications NotificationsConfig `yaml:"notifications"`            // notifier definitions
+	Routes        []RouteConfig       `yaml:"routes,omitempty"`         // rules selecting notifiers per notification
+	Connection    ConnectionConfig    `yaml:"connection,omitempty"`     // network settings of checks and notifiers
 	State         StateConfig         `yaml:"state"`
 	Log           LogConfig           `yaml:"log"`
+
+	// Files lists every file the configuration was loaded from
+	Files []string `yaml:"-"`
+
+	// dirs lists the domains directories and include glob directories,
+	// whose contents can change which files are loaded
+	dirs []string
+
+	// static holds the domains defined in config files, Domains adds the
+	// ones loaded from DomainSources
+	static []DomainConfig
 }
 
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
-	return &Config{
+	cfg := &Config{
 		ReminderDays: []int{30, 14, 7, 1},
-		State: StateConfig{
-			CooldownHours: 24,
-		},
-		Log: LogConfig{
-			Level: "info",
-		},
 	}
+	ApplyDefaults(&cfg.Connection)
+	ApplyDefaults(&cfg.State)
+	ApplyDefaults(&cfg.Log)
+	return cfg
 }
 
 // CheckResult holds the result of a certificate check
@@ -97,6 +511,20 @@ type CheckResult struct {
 	Domain        DomainConfig
 	Success       bool
 	Error         error
+	Attempts      int      // connection attempts, more than 1 after retries
+	Warnings      []string // problems that did not fail the check
 	Expiry        time.Time
 	DaysRemaining float64
-}
\ No newline at end of file
+	Certificate   CertificateInfo
+}
+
+// CertificateInfo describes the leaf certificate and the chain presented
+type CertificateInfo struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	DNSNames     []string
+	Chain        []string // subjects of the presented chain, leaf first
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// logLevels are the accepted values of log.level
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// validate checks the merged top-level settings and domain sources. Domains
+// are checked by finishDomain and notifiers when they are built.
+func (l *loader) validate() []error {
+	var errs []error
+	at := func(key string) string {
+		if where, ok := l.setBy[key]; ok {
+			return where
+		}
+		return l.cfg.Files[0]
+	}
+
+	if err := checkReminderDays(l.cfg.ReminderDays); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", at("reminder_days"), err))
+	}
+	if err := checkConnection(l.cfg.Connection); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", at("connection"), err))
+	}
+	if l.cfg.Connection.NotifierTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%s: notifier_timeout must not be negative", at("connection")))
+	}
+	if l.cfg.State.CooldownHours < 0 {
+		errs = append(errs, fmt.Errorf("%s: state.cooldown_hours must not be negative", at("state")))
+	}
+	if l.cfg.Log.Level != "" && !containsString(logLevels, l.cfg.Log.Level) {
+		errs = append(errs, fmt.Errorf("%s: unknown log.level %q (use debug, info, warn or error)", at("log"), l.cfg.Log.Level))
+	}
+
+	for _, src := range l.cfg.DomainSources {
+		switch {
+		case src.Path == "":
+			errs = append(errs, fmt.Errorf("domain source of type %q is missing path", src.Type))
+		case src.Type != "csv" && src.Type != "text" && src.Type != "json":
+			errs = append(errs, fmt.Errorf("%s: unknown domain source type %q (use csv, text or json)", src.Path, src.Type))
+		case utf8.RuneCountInString(src.Delimiter) > 1:
+			errs = append(errs, fmt.Errorf("%s: delimiter must be a single character", src.Path))
+		}
+	}
+	return errs
+}
+
+// checkConnection validates the settings shared by global and per-domain
+// connection settings
+func checkConnection(c ConnectionConfig) error {
+	var errs []error
+	if c.DialTimeout < 0 || c.HandshakeTimeout < 0 || c.RetryBackoff < 0 {
+		errs = append(errs, fmt.Errorf("connection timeouts and retry_backoff must not be negative"))
+	}
+	if c.Retries < 0 {
+		errs = append(errs, fmt.Errorf("retries must not be negative"))
+	}
+	if c.SourceAddress != "" && net.ParseIP(c.SourceAddress) == nil {
+		errs = append(errs, fmt.Errorf("source_address %q is not an IP address", c.SourceAddress))
+	}
+	if c.Proxy != "" && c.Proxy != "none" {
+		if _, err := ParseProxy(c.Proxy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseProxy parses a proxy URL of a supported scheme
+func ParseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		// The error of url.Parse repeats the URL, which may hold a password
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy %q must be an http, https, socks5 or socks5h URL", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q has no host", u.Redacted())
+	}
+	return u, nil
+}
+
+// checkReminderDays makes sure reminder days are not negative
+func checkReminderDays(days []int) error {
+	for _, day := range days {
+		if day < 0 {
+			return fmt.Errorf("reminder_days must not be negative, got %d", day)
+		}
+	}
+	return nil
+}
+
+// checkFileDomain checks the settings of a domain that reads its
+// certificates from disk
+func checkFileDomain(d *DomainConfig) []error {
+	var errs []error
+	if d.Port != 0 {
+		errs = append(errs, fmt.Errorf("%s: domain %s: port does not apply to certificate files", d.Source, d.File))
+	}
+	if _, err := filepath.Match(d.File, ""); err != nil {
+		errs = append(errs, fmt.Errorf("%s: domain %s: invalid file pattern: %w", d.Source, d.File, err))
+	}
+	return errs
+}
@@ -2,8 +2,12 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/checker"
@@ -14,9 +18,12 @@ import (
 
 // Engine orchestrates the certificate checking and notification process
 type Engine struct {
+	// mu guards config, notifier and router, which Reload replaces
+	mu       sync.RWMutex
 	config   *config.Config
 	checker  *checker.Checker
 	notifier *notifier.Manager
+	router   *notifier.Router
 	state    *state.Manager
 	logger   *slog.Logger
 }
@@ -29,23 +36,150 @@ func NewEngine(cfg *config.Config, logger *slog.Logger) (*Engine, error) {
 		return nil, fmt.Errorf("failed to create state manager: %w", err)
 	}
 
-	// Create notifier manager
-	notifierManager, err := notifier.BuildNotifiers(cfg)
+	notifierManager, router, err := buildRouting(cfg, notifierEnv(cfg, stateManager))
 	if err != nil {
-		return nil, fmt.Errorf("failed to build notifiers: %w", err)
+		return nil, err
 	}
 
 	return &Engine{
 		config:   cfg,
 		checker:  checker.NewChecker(),
 		notifier: notifierManager,
+		router:   router,
 		state:    stateManager,
 		logger:   logger,
 	}, nil
 }
 
+// Validate checks the parts of a configuration LoadConfig cannot: that every
+// enabled notifier can be created and that routes and domains only refer to
+// enabled notifiers. All problems are reported together. Nothing is sent and
+// the state file is not touched.
+func Validate(cfg *config.Config) error {
+	_, _, err := buildRouting(cfg, notifierEnv(cfg, nil))
+	return err
+}
+
+// ValidateFile loads and validates a configuration file. It is meant for a
+// validate command or a CI job that fails on any configuration problem.
+func ValidateFile(path string) error {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return Validate(cfg)
+}
+
+// notifierEnv returns the shared dependencies of the notifiers. Threads
+// may be nil.
+func notifierEnv(cfg *config.Config, threads notifier.ThreadStore) notifier.Env {
+	env := notifier.Env{
+		Threads:     threads,
+		HTTPTimeout: time.Duration(cfg.Connection.NotifierTimeout) * time.Second,
+	}
+	switch proxy := cfg.Connection.Proxy; proxy {
+	case "":
+	case "none":
+		env.Proxy = func(*http.Request) (*url.URL, error) { return nil, nil }
+	default:
+		// The proxy was validated when the configuration was loaded
+		if u, err := config.ParseProxy(proxy); err == nil {
+			env.Proxy = http.ProxyURL(u)
+		}
+	}
+	return env
+}
+
+// buildRouting creates the notifiers and routing rules, making sure routing
+// only refers to enabled notifiers
+func buildRouting(cfg *config.Config, env notifier.Env) (*notifier.Manager, *notifier.Router, error) {
+	var errs []error
+	notifierManager, err := notifier.BuildNotifiers(cfg, env)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	router, err := notifier.NewRouter(cfg.Routes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// Check references even if some notifiers failed, against those defined
+	has := func(name string) bool {
+		for _, def := range cfg.Notifications {
+			if def.Name == name && def.Enabled {
+				return true
+			}
+		}
+		return false
+	}
+	if notifierManager != nil {
+		has = notifierManager.Has
+	}
+	if err := checkDomainNotifiers(cfg.Domains, has); err != nil {
+		errs = append(errs, err)
+	}
+	for _, route := range cfg.Routes {
+		for _, name := range route.Notifiers {
+			if !has(name) {
+				errs = append(errs, withSource(route.Source, fmt.Errorf("route refers to unknown or disabled notifier %q", name)))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return notifierManager, router, nil
+}
+
+// checkDomainNotifiers makes sure domains only route to enabled notifiers
+func checkDomainNotifiers(domains []config.DomainConfig, has func(name string) bool) error {
+	var errs []error
+	for _, domain := range domains {
+		for _, name := range domain.Notifiers {
+			if !has(name) {
+				errs = append(errs, withSource(domain.Source, fmt.Errorf("domain %s routes to unknown or disabled notifier %q", domain.Key(), name)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withSource prefixes an error with the file:line it was found at, if known
+func withSource(source string, err error) error {
+	if source == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", source, err)
+}
+
+// refreshDomains reloads the domain sources, keeping the previous domains
+// when a source cannot be read or refers to unknown notifiers
+func (e *Engine) refreshDomains() {
+	if len(e.config.DomainSources) == 0 {
+		return
+	}
+	previous := e.config.Domains
+	if err := e.config.RefreshDomains(); err != nil {
+		e.logger.Error("Failed to refresh domain sources, keeping the previous domains", "error", err)
+		return
+	}
+	if err := checkDomainNotifiers(e.config.Domains, e.notifier.Has); err != nil {
+		e.logger.Error("Failed to refresh domain sources, keeping the previous domains", "error", err)
+		e.config.Domains = previous
+		return
+	}
+	e.logger.Debug("Refreshed domain sources", "domains", len(e.config.Domains))
+}
+
 // Run executes the certificate checking and notification process
 func (e *Engine) Run(ctx context.Context) error {
+	e.mu.Lock()
+	e.refreshDomains()
+	e.mu.Unlock()
+
+	// A reload waits for the run to finish
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	e.logger.Info("Starting SSL certificate monitoring", "domains", len(e.config.Domains))
 
 	var totalChecked, totalErrors, totalNotifications int
@@ -57,30 +191,40 @@ func (e *Engine) Run(ctx context.Context) error {
 		default:
 		}
 
-		domainName := domain.Name
-		if domainName == "" {
-			domainName = domain.Host
-		}
+		for _, result := range e.check(ctx, domain) {
+			// Certificate files are reported one by one
+			domain := result.Domain
+			domainName := domain.DisplayName()
+			totalChecked++
 
-		e.logger.Debug("Checking domain", "domain", domainName, "host", domain.Host, "port", domain.Port)
-		result := e.checker.CheckDomain(domain)
-		totalChecked++
+			if !result.Success {
+				e.logger.Warn("Failed to check domain", "domain", domainName, "attempts", result.Attempts, "error", result.Error)
+				totalErrors++
+				e.alertFailure(ctx, domain, result.Error)
+				continue
+			}
 
-		if !result.Success {
-			e.logger.Warn("Failed to check domain", "domain", domainName, "error", result.Error)
-			totalErrors++
-			continue
-		}
+			if result.Attempts > 1 {
+				e.logger.Debug("Domain check needed retries", "domain", domainName, "attempts", result.Attempts)
+			}
+			for _, warning := range result.Warnings {
+				e.logger.Warn("Certificate check warning", "domain", domainName, "warning", warning)
+			}
+			e.logger.Info("Certificate check successful",
+				"domain", domainName,
+				"days_remaining", result.DaysRemaining,
+				"expiry", result.Expiry.Format("2006-01-02"),
+			)
 
-		e.logger.Info("Certificate check successful",
-			"domain", domainName,
-			"days_remaining", result.DaysRemaining,
-			"expiry", result.Expiry.Format("2006-01-02"),
-		)
+			// Resolve the alert of a failed check and those the renewed
+			// certificate no longer qualifies for
+			e.resolveFailure(ctx, domain)
+			e.resolveRenewed(ctx, domain, result)
 
-		// Check thresholds and send notifications
-		notificationsSent := e.checkThresholds(domain, result)
-		totalNotifications += notificationsSent
+			// Check thresholds and send notifications
+			notificationsSent := e.checkThresholds(domain, result)
+			totalNotifications += notificationsSent
+		}
 	}
 
 	e.logger.Info("Monitoring completed",
@@ -92,86 +236,237 @@ func (e *Engine) Run(ctx context.Context) error {
 	return nil
 }
 
-// checkThresholds evaluates certificate expiry against configured thresholds
+// check fetches the certificate of a domain, or reads its certificate
+// files
+func (e *Engine) check(ctx context.Context, domain config.DomainConfig) []config.CheckResult {
+	if domain.File != "" {
+		e.logger.Debug("Checking certificate files", "domain", domain.DisplayName(), "file", domain.File)
+		return e.checker.CheckFiles(domain)
+	}
+	e.logger.Debug("Checking domain", "domain", domain.DisplayName(), "host", domain.Host, "port", domain.Port)
+	return []config.CheckResult{e.checker.CheckDomain(ctx, domain)}
+}
+
+// checkThresholds evaluates certificate expiry against configured thresholds.
+// An expired certificate gets a notification of its own, with threshold 0,
+// instead of one per threshold.
 func (e *Engine) checkThresholds(domain config.DomainConfig, result config.CheckResult) int {
-	domainName := domain.Name
-	if domainName == "" {
-		domainName = domain.Host
+	if result.DaysRemaining <= 0 {
+		e.logger.Error("Certificate has expired!",
+			"domain", domain.DisplayName(),
+			"days_remaining", result.DaysRemaining,
+			"expiry", result.Expiry.Format("2006-01-02"),
+		)
+		if e.notify(domain, result, 0) {
+			return 1
+		}
+		return 0
 	}
 
 	notificationsSent := 0
-
-	for _, threshold := range e.config.ReminderDays {
+	for _, threshold := range domain.ReminderDays {
 		// Check if days remaining is less than or equal to threshold
-		if result.DaysRemaining <= float64(threshold) && result.DaysRemaining > 0 {
-			// Check if we should send notification based on cooldown
-			if e.state.ShouldSend(domain.Host, threshold) {
-				e.logger.Info("Sending notification",
-					"domain", domainName,
-					"days_remaining", result.DaysRemaining,
-					"threshold", threshold,
-				)
-
-				notification := notifier.Notification{
-					Domain:        domain,
-					DaysRemaining: result.DaysRemaining,
-					Expiry:        result.Expiry,
-					Threshold:     threshold,
-				}
-
-				if err := e.notifier.Send(context.Background(), notification); err != nil {
-					e.logger.Error("Failed to send notification",
-						"domain", domainName,
-						"threshold", threshold,
-						"error", err,
-					)
-				} else {
-					// Mark as sent in state
-					if err := e.state.MarkSent(domain.Host, threshold); err != nil {
-						e.logger.Error("Failed to update state",
-							"domain", domainName,
-							"error", err,
-						)
-					} else {
-						notificationsSent++
-					}
-				}
-			} else {
-				lastSent, _ := e.state.GetLastSent(domain.Host, threshold)
-				e.logger.Debug("Skipping notification due to cooldown",
-					"domain", domainName,
-					"threshold", threshold,
-					"last_sent", lastSent.Format(time.RFC3339),
-				)
-			}
+		if result.DaysRemaining <= float64(threshold) && e.notify(domain, result, threshold) {
+			notificationsSent++
 		}
 	}
+	return notificationsSent
+}
 
-	// Check for expired certificates (days remaining <= 0)
-	if result.DaysRemaining <= 0 {
-		e.logger.Error("Certificate has expired!",
+// notify sends the notification for a threshold through the routing rules,
+// unless it is within its cooldown, and reports whether it was sent
+func (e *Engine) notify(domain config.DomainConfig, result config.CheckResult, threshold int) bool {
+	domainName := domain.DisplayName()
+
+	// Check if we should send notification based on cooldown
+	if !e.state.ShouldSendWithCooldown(domain.Key(), threshold, *domain.CooldownHours) {
+		lastSent, _ := e.state.GetLastSent(domain.Key(), threshold)
+		e.logger.Debug("Skipping notification due to cooldown",
+			"domain", domainName,
+			"threshold", threshold,
+			"last_sent", lastSent.Format(time.RFC3339),
+		)
+		return false
+	}
+
+	notification := notifier.Notification{
+		Domain:        domain,
+		DaysRemaining: result.DaysRemaining,
+		Expiry:        result.Expiry,
+		Threshold:     threshold,
+		Certificate:   result.Certificate,
+	}
+
+	targets, ok := e.targets(notification)
+	if !ok {
+		e.logger.Debug("Notification dropped by routing rules",
+			"domain", domainName,
+			"threshold", threshold,
+		)
+		return false
+	}
+
+	e.logger.Info("Sending notification",
+		"domain", domainName,
+		"days_remaining", result.DaysRemaining,
+		"threshold", threshold,
+		"notifiers", targets,
+	)
+
+	if err := e.notifier.SendTo(context.Background(), targets, notification); err != nil {
+		e.logger.Error("Failed to send notification",
+			"domain", domainName,
+			"threshold", threshold,
+			"error", err,
+		)
+		return false
+	}
+
+	// Mark as sent in state
+	if err := e.state.MarkSent(domain.Key(), threshold, targets); err != nil {
+		e.logger.Error("Failed to update state",
+			"domain", domainName,
+			"error", err,
+		)
+		return false
+	}
+	return true
+}
+
+// resolveRenewed resolves previously sent alerts for thresholds the
+// certificate is no longer within, i.e. once it has been renewed. Alerts are
+// resolved through the notifiers they were sent to rather than routed again,
+// since routing rules may match on days remaining, which has changed since.
+func (e *Engine) resolveRenewed(ctx context.Context, domain config.DomainConfig, result config.CheckResult) {
+	domainName := domain.DisplayName()
+
+	for _, threshold := range e.state.Thresholds(domain.Key()) {
+		if result.DaysRemaining <= float64(threshold) {
+			continue
+		}
+
+		e.logger.Info("Certificate renewed, resolving alerts",
 			"domain", domainName,
 			"days_remaining", result.DaysRemaining,
-			"expiry", result.Expiry.Format("2006-01-02"),
+			"threshold", threshold,
+		)
+
+		notification := notifier.Notification{
+			Domain:        domain,
+			DaysRemaining: result.DaysRemaining,
+			Expiry:        result.Expiry,
+			Threshold:     threshold,
+			Certificate:   result.Certificate,
+		}
+
+		// Keep the state entry on failure so the resolve is retried next run
+		if err := e.notifier.ResolveTo(ctx, e.state.SentTo(domain.Key(), threshold), notification); err != nil {
+			e.logger.Error("Failed to resolve alerts",
+				"domain", domainName,
+				"threshold", threshold,
+				"error", err,
+			)
+			continue
+		}
+		if err := e.state.ClearSent(domain.Key(), threshold); err != nil {
+			e.logger.Error("Failed to update state",
+				"domain", domainName,
+				"error", err,
+			)
+		}
+	}
+}
+
+// alertFailure alerts the notifiers that support it of a failed check,
+// once until the check recovers
+func (e *Engine) alertFailure(ctx context.Context, domain config.DomainConfig, checkErr error) {
+	domainName := domain.DisplayName()
+	if _, failed := e.state.Failed(domain.Key()); failed {
+		return
+	}
+
+	targets := domain.Notifiers
+	if len(targets) == 0 {
+		names, matched := e.router.RouteFailure(domain)
+		if matched && len(names) == 0 {
+			e.logger.Debug("Check failure alert dropped by routing rules", "domain", domainName)
+			return
+		}
+		targets = names
+	}
+
+	sent, err := e.notifier.SendFailureTo(ctx, targets, notifier.Notification{Domain: domain}, checkErr)
+	if err != nil {
+		e.logger.Error("Failed to send check failure alert",
+			"domain", domainName,
+			"error", err,
+		)
+	}
+	if len(sent) == 0 {
+		return
+	}
+	e.logger.Info("Sent check failure alert", "domain", domainName, "notifiers", sent)
+	if err := e.state.MarkFailed(domain.Key(), sent); err != nil {
+		e.logger.Error("Failed to update state",
+			"domain", domainName,
+			"error", err,
 		)
-		// Could send an urgent notification here if desired
 	}
+}
 
-	return notificationsSent
+// resolveFailure resolves the alert of a failed check once the domain has
+// been checked successfully, through the notifiers that were alerted
+func (e *Engine) resolveFailure(ctx context.Context, domain config.DomainConfig) {
+	domainName := domain.DisplayName()
+	names, failed := e.state.Failed(domain.Key())
+	if !failed {
+		return
+	}
+
+	e.logger.Info("Certificate check recovered, resolving alert", "domain", domainName)
+
+	// Keep the state entry on failure so the resolve is retried next run
+	if err := e.notifier.ResolveFailureTo(ctx, names, notifier.Notification{Domain: domain}); err != nil {
+		e.logger.Error("Failed to resolve check failure alert",
+			"domain", domainName,
+			"error", err,
+		)
+		return
+	}
+	if err := e.state.ClearFailed(domain.Key()); err != nil {
+		e.logger.Error("Failed to update state",
+			"domain", domainName,
+			"error", err,
+		)
+	}
+}
+
+// targets returns the notifiers a notification should be sent to. Domains
+// with an explicit notifier list bypass the routing rules; otherwise the
+// first matching rule decides and all notifiers are used when none match.
+// The second result is false when a rule matched but routes nowhere.
+func (e *Engine) targets(n notifier.Notification) ([]string, bool) {
+	if len(n.Domain.Notifiers) > 0 {
+		return n.Domain.Notifiers, true
+	}
+	names, matched := e.router.Route(n)
+	if !matched {
+		return nil, true
+	}
+	return names, len(names) > 0
 }
 
 // VerifyAll attempts to verify certificate chains for all domains
 func (e *Engine) VerifyAll() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	e.logger.Info("Verifying certificate chains")
 
 	for _, domain := range e.config.Domains {
-		domainName := domain.Name
-		if domainName == "" {
-			domainName = domain.Host
-		}
+		domainName := domain.DisplayName()
 
 		e.logger.Debug("Verifying domain", "domain", domainName)
-		if err := e.checker.VerifyCertificateChain(domain); err != nil {
+		if err := e.checker.VerifyCertificateChain(context.Background(), domain); err != nil {
 			e.logger.Warn("Certificate verification failed",
 				"domain", domainName,
 				"error", err,
@@ -182,4 +477,4 @@ func (e *Engine) VerifyAll() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// writeCertificate writes a self-signed PEM certificate expiring at notAfter
+func writeCertificate(t *testing.T, path string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: filepath.Base(path)},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// webhookRecorder is a webhook endpoint that records the payloads it receives
+type webhookRecorder struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func newWebhookRecorder(t *testing.T) *webhookRecorder {
+	rec := &webhookRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding webhook payload: %v", err)
+		}
+		rec.mu.Lock()
+		rec.payloads = append(rec.payloads, payload)
+		rec.mu.Unlock()
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func (rec *webhookRecorder) received() []map[string]interface{} {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]map[string]interface{}(nil), rec.payloads...)
+}
+
+// newTestEngine loads a config written to dir with a webhook notifier
+// pointing at rec
+func newTestEngine(t *testing.T, dir, domains string, rec *webhookRecorder) *Engine {
+	t.Helper()
+	cfg := domains + `
+reminder_days: [30, 7]
+state:
+  file: ` + filepath.Join(dir, "state.json") + `
+notifications:
+  webhook:
+    enabled: true
+    url: ` + rec.URL + `
+`
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEngine(c, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestRunNotifiesExpiredCertificates(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, filepath.Join(dir, "expired.pem"), time.Now().Add(-48*time.Hour))
+	rec := newWebhookRecorder(t)
+	e := newTestEngine(t, dir, "domains:\n  - file: expired.pem\n", rec)
+
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	payloads := rec.received()
+	if len(payloads) != 1 {
+		t.Fatalf("got %d notifications, want 1: %v", len(payloads), payloads)
+	}
+	if got := payloads[0]["severity"]; got != "expired" {
+		t.Errorf("severity = %v, want expired", got)
+	}
+	if got := payloads[0]["threshold"]; got != float64(0) {
+		t.Errorf("threshold = %v, want 0", got)
+	}
+
+	// The cooldown holds back the next reminder
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rec.received()); got != 1 {
+		t.Errorf("got %d notifications after the second run, want 1", got)
+	}
+}
+
+func TestRunNotifiesThresholds(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, filepath.Join(dir, "soon.pem"), time.Now().Add(5*24*time.Hour))
+	rec := newWebhookRecorder(t)
+	e := newTestEngine(t, dir, "domains:\n  - file: soon.pem\n", rec)
+
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	thresholds := map[float64]bool{}
+	for _, payload := range rec.received() {
+		thresholds[payload["threshold"].(float64)] = true
+	}
+	if len(thresholds) != 2 || !thresholds[30] || !thresholds[7] {
+		t.Errorf("notified thresholds = %v, want 30 and 7", thresholds)
+	}
+}
+
+// pagerDutyRecorder is an Events API stand-in that records the actions
+// and dedup keys of the events it receives
+type pagerDutyRecorder struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []string
+}
+
+func newPagerDutyRecorder(t *testing.T) *pagerDutyRecorder {
+	rec := &pagerDutyRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			EventAction string `json:"event_action"`
+			DedupKey    string `json:"dedup_key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		rec.mu.Lock()
+		rec.events = append(rec.events, event.EventAction+" "+event.DedupKey)
+		rec.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+// take returns the events received since the last call
+func (rec *pagerDutyRecorder) take() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	events := rec.events
+	rec.events = nil
+	return events
+}
+
+// newPagerDutyEngine loads a config written to dir with two PagerDuty
+// notifiers, routing a.pem to the first one only
+func newPagerDutyEngine(t *testing.T, dir string, first, second *pagerDutyRecorder) *Engine {
+	t.Helper()
+	cfg := `domains:
+  - file: a.pem
+reminder_days: [30, 7]
+state:
+  file: ` + filepath.Join(dir, "state.json") + `
+routes:
+  - match:
+      hosts: ["` + filepath.Join(dir, "a.pem") + `"]
+    notifiers: [first]
+notifications:
+  - name: first
+    type: pagerduty
+    routing_key: key
+    url: ` + first.URL + `
+  - name: second
+    type: pagerduty
+    routing_key: key
+    url: ` + second.URL + `
+`
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEngine(c, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestRunResolvesRenewedThroughRoutedNotifiers(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "a.pem")
+	writeCertificate(t, cert, time.Now().Add(5*24*time.Hour))
+	first, second := newPagerDutyRecorder(t), newPagerDutyRecorder(t)
+	e := newPagerDutyEngine(t, dir, first, second)
+
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(first.take()); got != 2 {
+		t.Errorf("got %d trigger events, want 2", got)
+	}
+	if got := second.take(); len(got) != 0 {
+		t.Fatalf("the routing rule did not match, the second notifier got %v", got)
+	}
+
+	writeCertificate(t, cert, time.Now().Add(60*24*time.Hour))
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	key := "ssl-cert-monitor/" + cert
+	want := []string{"resolve " + key + "/7", "resolve " + key + "/30"}
+	if got := first.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if got := second.take(); len(got) != 0 {
+		t.Errorf("the notifier the alerts were not routed to got %v", got)
+	}
+}
+
+func TestRunResolvesRecoveredChecks(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "a.pem")
+	writeFile(t, cert, "not a certificate")
+	first, second := newPagerDutyRecorder(t), newPagerDutyRecorder(t)
+	e := newPagerDutyEngine(t, dir, first, second)
+
+	key := "ssl-cert-monitor/" + cert + "/check"
+	for i := 0; i < 2; i++ {
+		if err := e.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := first.take(), []string{"trigger " + key}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events while failing = %v, want %v", got, want)
+	}
+
+	writeCertificate(t, cert, time.Now().Add(60*24*time.Hour))
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := first.take(), []string{"resolve " + key}; !reflect.DeepEqual(got, want) {
+		t.Errorf("events after recovering = %v, want %v", got, want)
+	}
+	if got := second.take(); len(got) != 0 {
+		t.Errorf("the notifier the alert was not routed to got %v", got)
+	}
+}
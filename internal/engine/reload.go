@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Reload loads the configuration file again and, if it is valid, replaces
+// the domains, notifiers and routing rules in one step. On error the
+// current configuration is kept. The state file and log settings are not
+// reloaded; changing them requires a restart.
+func (e *Engine) Reload() error {
+	e.mu.RLock()
+	current := e.config
+	e.mu.RUnlock()
+	if len(current.Files) == 0 {
+		return errors.New("configuration was not loaded from a file")
+	}
+
+	cfg, err := config.LoadConfig(current.Files[0])
+	if err != nil {
+		return err
+	}
+	notifierManager, router, err := buildRouting(cfg, notifierEnv(cfg, e.state))
+	if err != nil {
+		return err
+	}
+
+	if cfg.State.File != current.State.File {
+		e.logger.Warn("State file changed, restart to apply it", "state_file", current.State.File)
+		cfg.State.File = current.State.File
+	}
+	if cfg.Log != current.Log {
+		e.logger.Warn("Log settings changed, restart to apply them")
+	}
+
+	e.mu.Lock()
+	previous := e.config
+	e.config, e.notifier, e.router = cfg, notifierManager, router
+	e.mu.Unlock()
+
+	e.logChanges(previous, cfg)
+	return nil
+}
+
+// Watch polls the configuration files and the directories they are loaded
+// from every interval and reloads the configuration after a change. A
+// change is only acted on once the files have stopped changing for one
+// interval, so a file being written is not loaded half-way. Watch returns
+// when ctx is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := e.fingerprint()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := e.fingerprint()
+		if current != last {
+			last, pending = current, true
+			continue
+		}
+		if !pending {
+			continue
+		}
+		pending = false
+
+		e.logger.Info("Configuration changed, reloading")
+		if err := e.Reload(); err != nil {
+			e.logger.Error("Failed to reload configuration, keeping the current one", "error", err)
+			continue
+		}
+		// The reloaded configuration may include other files
+		last = e.fingerprint()
+	}
+}
+
+// fingerprint describes the modification state of the watched paths
+func (e *Engine) fingerprint() string {
+	e.mu.RLock()
+	paths := e.config.WatchPaths()
+	e.mu.RUnlock()
+
+	var sb strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s missing\n", path)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %d %d\n", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return sb.String()
+}
+
+// logChanges logs what differs between two configurations
+func (e *Engine) logChanges(previous, cfg *config.Config) {
+	added, removed, changed := diffDomains(previous.Domains, cfg.Domains)
+	notifiersAdded, notifiersRemoved, notifiersChanged := diffNotifiers(previous.Notifications, cfg.Notifications)
+
+	attrs := []interface{}{"domains", len(cfg.Domains)}
+	addList := func(key string, names []string) {
+		if len(names) > 0 {
+			attrs = append(attrs, key, names)
+		}
+	}
+	addList("domains_added", added)
+	addList("domains_removed", removed)
+	addList("domains_changed", changed)
+	addList("notifiers_added", notifiersAdded)
+	addList("notifiers_removed", notifiersRemoved)
+	addList("notifiers_changed", notifiersChanged)
+	if !reflect.DeepEqual(withoutSources(previous.Routes), withoutSources(cfg.Routes)) {
+		attrs = append(attrs, "routes", fmt.Sprintf("%d -> %d rules", len(previous.Routes), len(cfg.Routes)))
+	}
+	if !reflect.DeepEqual(previous.ReminderDays, cfg.ReminderDays) {
+		attrs = append(attrs, "reminder_days", fmt.Sprintf("%v -> %v", previous.ReminderDays, cfg.ReminderDays))
+	}
+	e.logger.Info("Configuration reloaded", attrs...)
+}
+
+// diffDomains compares two domain lists by endpoint or certificate file.
+// The source position is ignored, so moving a domain between files does not
+// count as a change.
+func diffDomains(previous, current []config.DomainConfig) (added, removed, changed []string) {
+	index := func(domains []config.DomainConfig) map[string]config.DomainConfig {
+		m := make(map[string]config.DomainConfig, len(domains))
+		for _, d := range domains {
+			d.Source = ""
+			m[d.Endpoint()] = d
+		}
+		return m
+	}
+	before, after := index(previous), index(current)
+
+	for _, d := range current {
+		endpoint := d.Endpoint()
+		old, ok := before[endpoint]
+		switch {
+		case !ok:
+			added = append(added, endpoint)
+		case !reflect.DeepEqual(old, after[endpoint]):
+			changed = append(changed, endpoint)
+		}
+	}
+	for _, d := range previous {
+		endpoint := d.Endpoint()
+		if _, ok := after[endpoint]; !ok {
+			removed = append(removed, endpoint)
+		}
+	}
+	return added, removed, changed
+}
+
+// withoutSources returns routes without their source positions, for
+// comparing them
+func withoutSources(routes []config.RouteConfig) []config.RouteConfig {
+	stripped := make([]config.RouteConfig, len(routes))
+	for i, route := range routes {
+		route.Source = ""
+		stripped[i] = route
+	}
+	return stripped
+}
+
+// diffNotifiers compares two notifier lists by name, including their
+// settings
+func diffNotifiers(previous, current config.NotificationsConfig) (added, removed, changed []string) {
+	describe := func(n config.NotifierConfig) string {
+		settings, _ := yaml.Marshal(&n.Settings)
+		return fmt.Sprintf("%s %t %s", n.Type, n.Enabled, settings)
+	}
+	before := make(map[string]string, len(previous))
+	for _, n := range previous {
+		before[n.Name] = describe(n)
+	}
+	after := make(map[string]bool, len(current))
+	for _, n := range current {
+		after[n.Name] = true
+		old, ok := before[n.Name]
+		switch {
+		case !ok:
+			added = append(added, n.Name)
+		case old != describe(n):
+			changed = append(changed, n.Name)
+		}
+	}
+	for _, n := range previous {
+		if !after[n.Name] {
+			removed = append(removed, n.Name)
+		}
+	}
+	return added, removed, changed
+}
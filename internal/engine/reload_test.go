@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// writeFile writes content to path, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newReloadEngine loads config.yaml from dir with domains from an empty
+// conf.d
+func newReloadEngine(t *testing.T, dir, domains string) *Engine {
+	t.Helper()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, reloadConfig(dir, domains))
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEngine(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// reloadConfig returns a config using dir for its state and domains_dir
+func reloadConfig(dir, domains string) string {
+	return "domains_dir: conf.d\n" + domains + "state:\n  file: " + filepath.Join(dir, "state.json") + "\n"
+}
+
+// endpoints returns the endpoints of the current configuration
+func (e *Engine) endpoints() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var endpoints []string
+	for _, d := range e.config.Domains {
+		endpoints = append(endpoints, fmt.Sprintf("%s:%d", d.Host, d.Port))
+	}
+	return endpoints
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	e := newReloadEngine(t, dir, "domains:\n  - host: a.example.com\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "b.yaml"), "- host: b.example.com\n")
+
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.example.com:443", "b.example.com:443"}
+	if got := e.endpoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints = %v, want %v", got, want)
+	}
+
+	// An invalid configuration keeps the current one
+	writeFile(t, filepath.Join(dir, "config.yaml"), reloadConfig(dir, "domains:\n  - host: a.example.com\n    prot: 1\n"))
+	if err := e.Reload(); err == nil {
+		t.Error("reloading an invalid configuration succeeded")
+	}
+	if got := e.endpoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints after a failed reload = %v, want %v", got, want)
+	}
+
+	// The state file is not changed by a reload
+	writeFile(t, filepath.Join(dir, "config.yaml"), "domains:\n  - host: a.example.com\nstate:\n  file: "+filepath.Join(dir, "other.json")+"\n")
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if e.config.State.File != filepath.Join(dir, "state.json") {
+		t.Errorf("state file = %q", e.config.State.File)
+	}
+}
+
+func TestReloadWithoutFile(t *testing.T) {
+	e, err := NewEngine(config.DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err == nil {
+		t.Error("reload without a config file succeeded")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	e := newReloadEngine(t, dir, "domains:\n  - host: a.example.com\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- e.Watch(ctx, 20*time.Millisecond) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// Let Watch record the files before changing them
+	time.Sleep(100 * time.Millisecond)
+
+	writeFile(t, filepath.Join(dir, "conf.d", "b.yaml"), "- host: b.example.com\n")
+	want := []string{"a.example.com:443", "b.example.com:443"}
+	deadline := time.Now().Add(5 * time.Second)
+	for !reflect.DeepEqual(e.endpoints(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("endpoints = %v, want %v", e.endpoints(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDiffDomains(t *testing.T) {
+	previous := []config.DomainConfig{
+		{Host: "a.example.com", Port: 443, Source: "config.yaml:3"},
+		{Host: "b.example.com", Port: 443},
+		{Host: "c.example.com", Port: 443},
+	}
+	current := []config.DomainConfig{
+		{Host: "a.example.com", Port: 443, Source: "conf.d/a.yaml:1"},
+		{Host: "c.example.com", Port: 443, Group: "web"},
+		{Host: "d.example.com", Port: 443},
+	}
+	added, removed, changed := diffDomains(previous, current)
+	if !reflect.DeepEqual(added, []string{"d.example.com:443"}) {
+		t.Errorf("added = %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"b.example.com:443"}) {
+		t.Errorf("removed = %v", removed)
+	}
+	if !reflect.DeepEqual(changed, []string{"c.example.com:443"}) {
+		t.Errorf("changed = %v", changed)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/config"
@@ -13,8 +14,19 @@ import (
 
 // DiscordNotifier sends notifications to Discord via webhook
 type DiscordNotifier struct {
-	config config.DiscordConfig
-	client *http.Client
+	config    config.DiscordConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+func init() {
+	Register("discord", config.DiscordConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.DiscordConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewDiscordNotifier(cfg)
+	})
 }
 
 // NewDiscordNotifier creates a new Discord notifier
@@ -22,9 +34,15 @@ func NewDiscordNotifier(cfg config.DiscordConfig) (*DiscordNotifier, error) {
 	if cfg.WebhookURL == "" {
 		return nil, fmt.Errorf("webhook URL is required")
 	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DiscordNotifier{
-		config: cfg,
-		client: &http.Client{Timeout: 10 * time.Second},
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
 	}, nil
 }
 
@@ -38,12 +56,12 @@ type discordMessage struct {
 
 // discordEmbed represents a Discord embed
 type discordEmbed struct {
-	Title       string               `json:"title,omitempty"`
-	Description string               `json:"description,omitempty"`
-	Color       int                  `json:"color,omitempty"`
-	Fields      []discordEmbedField  `json:"fields,omitempty"`
-	Timestamp   string               `json:"timestamp,omitempty"`
-	Footer      *discordEmbedFooter  `json:"footer,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color,omitempty"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
 }
 
 // discordEmbedField represents a field within a Discord embed
@@ -60,7 +78,11 @@ type discordEmbedFooter struct {
 
 // Send sends a notification to Discord
 func (d *DiscordNotifier) Send(ctx context.Context, n Notification) error {
-	message := d.buildMessage(n)
+	text, err := d.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := d.buildMessage(n, text)
 	payload, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Discord message: %w", err)
@@ -90,25 +112,19 @@ func (d *DiscordNotifier) Name() string {
 	return "Discord"
 }
 
-// buildMessage constructs the Discord message
-func (d *DiscordNotifier) buildMessage(n Notification) discordMessage {
-	domainName := n.Domain.Name
-	if domainName == "" {
-		domainName = n.Domain.Host
-	}
+// httpClient returns the client used for requests
+func (d *DiscordNotifier) httpClient() *http.Client {
+	return d.client
+}
 
-	// Determine color based on urgency
-	color := 0x00FF00 // Green
-	if n.DaysRemaining <= 7 {
-		color = 0xFF0000 // Red
-	} else if n.DaysRemaining <= 30 {
-		color = 0xFFA500 // Orange
-	}
+// buildMessage constructs the Discord message
+func (d *DiscordNotifier) buildMessage(n Notification, text messageText) discordMessage {
+	domainName := n.Domain.DisplayName()
 
 	embed := discordEmbed{
-		Title:       "⚠️ SSL Certificate Expiry Alert",
-		Description: fmt.Sprintf("Certificate for **%s** is expiring soon!", domainName),
-		Color:       color,
+		Title:       text.titleOr("⚠️ SSL Certificate Expiry Alert"),
+		Description: text.bodyOr(expiryHeadline("**"+domainName+"**", n)),
+		Color:       n.Severity().Color(),
 		Fields: []discordEmbedField{
 			{
 				Name:   "Domain",
@@ -117,7 +133,7 @@ func (d *DiscordNotifier) buildMessage(n Notification) discordMessage {
 			},
 			{
 				Name:   "Host",
-				Value:  fmt.Sprintf("%s:%d", n.Domain.Host, n.Domain.Port),
+				Value:  n.Endpoint(),
 				Inline: true,
 			},
 			{
@@ -147,9 +163,19 @@ func (d *DiscordNotifier) buildMessage(n Notification) discordMessage {
 		},
 	}
 
+	if n.Domain.Group != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Group", Value: n.Domain.Group, Inline: true})
+	}
+	if len(n.Domain.Tags) > 0 {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Tags", Value: strings.Join(n.Domain.Tags, ", "), Inline: true})
+	}
+	if len(n.Domain.Labels) > 0 {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Labels", Value: formatLabels(n.Domain.Labels)})
+	}
+
 	return discordMessage{
 		Username:  d.config.Username,
 		AvatarURL: d.config.AvatarURL,
 		Embeds:    []discordEmbed{embed},
 	}
-}
\ No newline at end of file
+}
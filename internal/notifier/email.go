@@ -1,9 +1,22 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +25,22 @@ import (
 
 // EmailNotifier sends notifications via SMTP email
 type EmailNotifier struct {
-	config config.EmailConfig
+	config    config.EmailConfig
+	templates *messageTemplates
+
+	// Envelope addresses, without display names
+	from       string
+	recipients []string
+}
+
+func init() {
+	Register("email", config.EmailConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.EmailConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewEmailNotifier(cfg)
+	})
 }
 
 // NewEmailNotifier creates a new email notifier
@@ -23,49 +51,103 @@ func NewEmailNotifier(cfg config.EmailConfig) (*EmailNotifier, error) {
 	if cfg.From == "" {
 		return nil, fmt.Errorf("from address is required")
 	}
-	if cfg.To == "" {
+	if len(cfg.To) == 0 {
 		return nil, fmt.Errorf("to address is required")
 	}
+	// Bcc recipients only appear in the envelope
+	var envelope []string
+	for _, addr := range append(append(append([]string{cfg.From}, cfg.To...), cfg.Cc...), cfg.Bcc...) {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+		envelope = append(envelope, parsed.Address)
+	}
+
+	// Resolve the TLS mode, honoring the older use_tls flag. Without either,
+	// STARTTLS is used when the server offers it.
+	switch cfg.TLS {
+	case "":
+		switch {
+		case cfg.UseTLS:
+			cfg.TLS = "starttls"
+		case cfg.SMTPPort == 465:
+			cfg.TLS = "tls"
+		}
+	case "none", "starttls", "tls":
+	default:
+		return nil, fmt.Errorf("unknown TLS mode %q (use none, starttls or tls)", cfg.TLS)
+	}
+	if cfg.SMTPPort == 0 {
+		switch cfg.TLS {
+		case "tls":
+			cfg.SMTPPort = 465
+		case "starttls":
+			cfg.SMTPPort = 587
+		default:
+			cfg.SMTPPort = 25
+		}
+	}
+
+	switch cfg.Auth {
+	case "":
+		cfg.Auth = "none"
+		if cfg.Username != "" {
+			cfg.Auth = "plain"
+		}
+	case "none", "plain", "login", "cram-md5":
+	default:
+		return nil, fmt.Errorf("unknown auth mechanism %q (use none, plain, login or cram-md5)", cfg.Auth)
+	}
+	if cfg.Auth != "none" && cfg.Username == "" {
+		return nil, fmt.Errorf("username is required for %s auth", cfg.Auth)
+	}
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EmailNotifier{
-		config: cfg,
+		config:     cfg,
+		templates:  templates,
+		from:       envelope[0],
+		recipients: envelope[1:],
 	}, nil
 }
 
 // Send sends an email notification
 func (e *EmailNotifier) Send(ctx context.Context, n Notification) error {
-	domainName := n.Domain.Name
-	if domainName == "" {
-		domainName = n.Domain.Host
-	}
-
-	subject := fmt.Sprintf("SSL Certificate Expiry Alert: %s (%.1f days remaining)", domainName, n.DaysRemaining)
-	body := e.buildEmailBody(n)
-
-	message := []byte(fmt.Sprintf(
-		"From: %s\r\n"+
-			"To: %s\r\n"+
-			"Subject: %s\r\n"+
-			"Content-Type: text/plain; charset=UTF-8\r\n"+
-			"\r\n"+
-			"%s",
-		e.config.From,
-		e.config.To,
-		subject,
-		body,
-	))
-
-	auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.SMTPHost)
-	addr := fmt.Sprintf("%s:%d", e.config.SMTPHost, e.config.SMTPPort)
+	domainName := n.Domain.DisplayName()
 
-	var err error
-	if e.config.UseTLS {
-		err = smtp.SendMail(addr, auth, e.config.From, []string{e.config.To}, message)
-	} else {
-		// For non-TLS connections (not recommended)
-		err = smtp.SendMail(addr, auth, e.config.From, []string{e.config.To}, message)
+	text, err := e.templates.render(n)
+	if err != nil {
+		return err
 	}
 
+	// The subject template takes precedence over the generic title
+	remaining := fmt.Sprintf("%.1f days remaining", n.DaysRemaining)
+	if n.Severity() == SeverityExpired {
+		remaining = "expired"
+	}
+	subject := text.titleOr(fmt.Sprintf("SSL Certificate Expiry Alert: %s (%s)", domainName, remaining))
+	if text.Subject != "" {
+		subject = text.Subject
+	}
+	htmlBody := text.HTMLBody
+	if htmlBody == "" {
+		htmlBody, err = e.buildHTMLBody(n)
+		if err != nil {
+			return err
+		}
+	}
+
+	message, err := e.buildMessage(strings.TrimSpace(subject), text.bodyOr(e.buildEmailBody(n)), htmlBody)
 	if err != nil {
+		return err
+	}
+
+	if err := e.sendMail(ctx, message); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 	return nil
@@ -76,6 +158,245 @@ func (e *EmailNotifier) Name() string {
 	return "Email"
 }
 
+// sendMail delivers the message over SMTP using the configured TLS mode and
+// auth mechanism
+func (e *EmailNotifier) sendMail(ctx context.Context, message []byte) error {
+	addr := net.JoinHostPort(e.config.SMTPHost, strconv.Itoa(e.config.SMTPPort))
+	tlsConfig := &tls.Config{
+		ServerName:         e.config.SMTPHost,
+		InsecureSkipVerify: e.config.SkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if e.config.TLS == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	// Bound the whole conversation, not just the dial
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, e.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	switch ok, _ := client.Extension("STARTTLS"); {
+	case e.config.TLS == "starttls" && !ok:
+		return errors.New("server does not support STARTTLS")
+	case e.config.TLS == "starttls" || e.config.TLS == "" && ok:
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if auth := e.auth(); auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("server does not support authentication")
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+	for _, rcpt := range e.recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// auth returns the configured SMTP auth mechanism, or nil for none
+func (e *EmailNotifier) auth() smtp.Auth {
+	switch e.config.Auth {
+	case "plain":
+		return smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.SMTPHost)
+	case "login":
+		return &loginAuth{username: e.config.Username, password: e.config.Password, host: e.config.SMTPHost}
+	case "cram-md5":
+		return smtp.CRAMMD5Auth(e.config.Username, e.config.Password)
+	default:
+		return nil
+	}
+}
+
+// buildMessage assembles a multipart/alternative message with a plain text
+// and an HTML part
+func (e *EmailNotifier) buildMessage(subject, textBody, htmlBody string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + e.config.From,
+		"To: " + strings.Join(e.config.To, ", "),
+	}
+	if len(e.config.Cc) > 0 {
+		headers = append(headers, "Cc: "+strings.Join(e.config.Cc, ", "))
+	}
+	headers = append(headers,
+		"Subject: "+mime.QEncoding.Encode("UTF-8", subject),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"Message-ID: "+messageID(e.config.From),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary="+mw.Boundary(),
+	)
+	header := strings.Join(headers, "\r\n") + "\r\n\r\n"
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", textBody},
+		{"text/html; charset=UTF-8", htmlBody},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(header), buf.Bytes()...), nil
+}
+
+// messageID generates a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// emailHTMLTemplate renders the HTML part of the email as a table
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, Helvetica, sans-serif; color: #333333;">
+  <h2 style="color: {{.Color}};">&#9888;&#65039; SSL Certificate Expiry Alert</h2>
+  <p>Certificate for <strong>{{.Domain}}</strong> {{if .Expired}}has <strong>expired</strong>{{else}}expires in <strong>{{printf "%.1f" .DaysRemaining}} days</strong>{{end}}.</p>
+  <table cellpadding="6" cellspacing="0" style="border-collapse: collapse; border: 1px solid #dddddd;">
+    {{- range .Fields}}
+    <tr>
+      <th align="left" style="border: 1px solid #dddddd; background: #f5f5f5;">{{.Label}}</th>
+      <td style="border: 1px solid #dddddd;">{{.Value}}</td>
+    </tr>
+    {{- end}}
+  </table>
+  <p style="color: {{.Color}};"><strong>Action Required:</strong> {{.Action}}</p>
+  <p style="font-size: 12px; color: #888888;">This is an automated notification from SSL Certificate Monitor.</p>
+</body>
+</html>
+`))
+
+// buildHTMLBody renders the HTML email body
+func (e *EmailNotifier) buildHTMLBody(n Notification) (string, error) {
+	domainName := n.Domain.DisplayName()
+
+	var buf bytes.Buffer
+	err := emailHTMLTemplate.Execute(&buf, struct {
+		Domain        string
+		DaysRemaining float64
+		Expired       bool
+		Color         string
+		Action        string
+		Fields        []messageField
+	}{
+		Domain:        domainName,
+		DaysRemaining: n.DaysRemaining,
+		Expired:       n.Severity() == SeverityExpired,
+		Color:         n.Severity().HexColor(),
+		Action:        n.Severity().Action(),
+		Fields:        messageFields(n),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render HTML body: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// loginAuth implements the LOGIN auth mechanism, which net/smtp lacks but
+// some servers (e.g. Office 365) still require
+type loginAuth struct {
+	username, password, host string
+}
+
+// Start begins LOGIN authentication, refusing unencrypted connections
+// to remote hosts like smtp.PlainAuth does
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server's username and password challenges
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// isLocalhost reports whether host refers to the local machine
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
 // buildEmailBody constructs the email body
 func (e *EmailNotifier) buildEmailBody(n Notification) string {
 	var sb strings.Builder
@@ -83,22 +404,29 @@ func (e *EmailNotifier) buildEmailBody(n Notification) string {
 	sb.WriteString("SSL Certificate Expiry Alert\n")
 	sb.WriteString("============================\n\n")
 	sb.WriteString(fmt.Sprintf("Domain: %s\n", n.Domain.Name))
-	sb.WriteString(fmt.Sprintf("Host: %s:%d\n", n.Domain.Host, n.Domain.Port))
+	sb.WriteString(fmt.Sprintf("Host: %s\n", n.Endpoint()))
 	sb.WriteString(fmt.Sprintf("Days Remaining: %.1f\n", n.DaysRemaining))
 	sb.WriteString(fmt.Sprintf("Expiry Date: %s\n", n.Expiry.Format("2006-01-02 15:04:05 MST")))
 	sb.WriteString(fmt.Sprintf("Threshold: %d days\n", n.Threshold))
 	sb.WriteString(fmt.Sprintf("Check Time: %s\n", time.Now().Format("2006-01-02 15:04:05 MST")))
+	if n.Domain.Group != "" {
+		sb.WriteString(fmt.Sprintf("Group: %s\n", n.Domain.Group))
+	}
+	if len(n.Domain.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(n.Domain.Tags, ", ")))
+	}
+	if len(n.Domain.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf("Labels: %s\n", formatLabels(n.Domain.Labels)))
+	}
 	sb.WriteString("\n")
 	sb.WriteString("Action Required:\n")
-	if n.DaysRemaining <= 7 {
-		sb.WriteString("  ⚠️  Certificate expires soon! Please renew immediately.\n")
-	} else if n.DaysRemaining <= 30 {
-		sb.WriteString("  ⚠️  Certificate expires within 30 days. Plan for renewal.\n")
-	} else {
-		sb.WriteString("  ℹ️  Certificate expiry approaching. Monitor regularly.\n")
+	icon := "ℹ️"
+	if n.Severity() != SeverityInfo {
+		icon = "⚠️"
 	}
+	sb.WriteString(fmt.Sprintf("  %s  %s\n", icon, n.Severity().Action()))
 	sb.WriteString("\n")
 	sb.WriteString("This is an automated notification from SSL Certificate Monitor.\n")
 
 	return sb.String()
-}
\ No newline at end of file
+}
@@ -0,0 +1,232 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// smtpServer is a minimal SMTP server that records the commands and the
+// message it receives
+type smtpServer struct {
+	listener net.Listener
+	tls      *tls.Config
+	starttls bool // offer STARTTLS
+
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+func newSMTPServer(t *testing.T, starttls bool) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Borrow the self-signed certificate of an httptest server
+	ts := httptest.NewTLSServer(nil)
+	cert := ts.TLS.Certificates[0]
+	ts.Close()
+
+	s := &smtpServer{
+		listener: l,
+		tls:      &tls.Config{Certificates: []tls.Certificate{cert}},
+		starttls: starttls,
+	}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	secure := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch verb := strings.ToUpper(strings.Fields(line)[0]); verb {
+		case "EHLO":
+			reply("250-localhost")
+			if s.starttls && !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			reply("235 authenticated")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// received returns the recorded commands and message
+func (s *smtpServer) received() ([]string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), s.data
+}
+
+func hasCommand(commands []string, prefix string) bool {
+	for _, c := range commands {
+		if strings.HasPrefix(c, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEmailEnvelopeAddresses(t *testing.T) {
+	srv := newSMTPServer(t, false)
+	n, err := NewEmailNotifier(config.EmailConfig{
+		SMTPHost: "127.0.0.1",
+		SMTPPort: srv.port(),
+		From:     "Alerts <alerts@example.com>",
+		To:       config.AddressList{"Ops Team <ops@example.com>"},
+		Bcc:      config.AddressList{"audit@example.com"},
+		TLS:      "none",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, data := srv.received()
+	for _, want := range []string{"MAIL FROM:<alerts@example.com>", "RCPT TO:<ops@example.com>", "RCPT TO:<audit@example.com>"} {
+		if !hasCommand(commands, want) {
+			t.Errorf("missing %q in %q", want, commands)
+		}
+	}
+	if !strings.Contains(data, "From: Alerts <alerts@example.com>\r\n") {
+		t.Errorf("From header lost its display name:\n%s", data)
+	}
+	if strings.Contains(data, "audit@example.com") {
+		t.Errorf("Bcc recipient appears in the message:\n%s", data)
+	}
+}
+
+func TestEmailTLSModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		tls      string
+		offered  bool
+		starttls bool
+		wantErr  string
+	}{
+		{name: "unset upgrades when offered", offered: true, starttls: true},
+		{name: "unset stays plain when not offered"},
+		{name: "starttls", tls: "starttls", offered: true, starttls: true},
+		{name: "starttls required", tls: "starttls", wantErr: "does not support STARTTLS"},
+		{name: "none", tls: "none", offered: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSMTPServer(t, tt.offered)
+			n, err := NewEmailNotifier(config.EmailConfig{
+				SMTPHost:   "127.0.0.1",
+				SMTPPort:   srv.port(),
+				From:       "alerts@example.com",
+				To:         config.AddressList{"ops@example.com"},
+				Username:   "user",
+				Password:   "secret",
+				TLS:        tt.tls,
+				SkipVerify: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = n.Send(context.Background(), testNotification())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			commands, _ := srv.received()
+			if got := hasCommand(commands, "STARTTLS"); got != tt.starttls {
+				t.Errorf("STARTTLS used = %v, want %v (%q)", got, tt.starttls, commands)
+			}
+		})
+	}
+}
+
+func TestEmailDefaultPort(t *testing.T) {
+	tests := []struct {
+		tls  string
+		port int
+	}{
+		{"", 25},
+		{"none", 25},
+		{"starttls", 587},
+		{"tls", 465},
+	}
+	for _, tt := range tests {
+		n, err := NewEmailNotifier(config.EmailConfig{SMTPHost: "mail.example.com", From: "a@example.com", To: config.AddressList{"b@example.com"}, TLS: tt.tls})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.config.SMTPPort != tt.port {
+			t.Errorf("tls %q: port = %d, want %d", tt.tls, n.config.SMTPPort, tt.port)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// maxExecStderr limits how much stderr is kept for error messages
+const maxExecStderr = 4096
+
+// execWaitDelay is how long to wait for children that inherited stderr
+// after the command exited or was killed
+const execWaitDelay = 5 * time.Second
+
+// lockPollInterval is how often a lock file held by another process is
+// checked
+const lockPollInterval = 100 * time.Millisecond
+
+func init() {
+	Register("exec", config.ExecConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.ExecConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewExecNotifier(cfg)
+	})
+}
+
+// ExecNotifier runs a local command for each notification. The notification
+// is passed as SSL_MONITOR_* environment variables and as JSON on stdin.
+type ExecNotifier struct {
+	config    config.ExecConfig
+	timeout   time.Duration
+	slots     chan struct{}
+	templates *messageTemplates
+}
+
+// NewExecNotifier creates a new exec notifier
+func NewExecNotifier(cfg config.ExecConfig) (*ExecNotifier, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative")
+	}
+	if cfg.MaxConcurrent < 0 {
+		return nil, fmt.Errorf("max_concurrent must not be negative")
+	}
+	config.ApplyDefaults(&cfg)
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecNotifier{
+		config:    cfg,
+		timeout:   time.Duration(cfg.Timeout) * time.Second,
+		slots:     make(chan struct{}, cfg.MaxConcurrent),
+		templates: templates,
+	}, nil
+}
+
+// Send runs the command for a notification
+func (e *ExecNotifier) Send(ctx context.Context, n Notification) error {
+	return e.run(ctx, "trigger", n)
+}
+
+// Resolve runs the command with SSL_MONITOR_EVENT=resolve once the
+// certificate has been renewed, e.g. to close a ticket
+func (e *ExecNotifier) Resolve(ctx context.Context, n Notification) error {
+	return e.run(ctx, "resolve", n)
+}
+
+// Name returns the name of the notifier
+func (e *ExecNotifier) Name() string {
+	return "Exec"
+}
+
+// run executes the command once a slot is free and, with lock_file set, no
+// other process is running one. A non-zero exit code is reported as an
+// error including the command's stderr.
+func (e *ExecNotifier) run(ctx context.Context, event string, n Notification) error {
+	text, err := e.templates.render(n)
+	if err != nil {
+		return err
+	}
+	stdin, err := e.buildInput(event, n, text)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case e.slots <- struct{}{}:
+		defer func() { <-e.slots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if e.config.LockFile != "" {
+		// A command is killed after the timeout, so an older lock was
+		// left behind by a process that died
+		unlock, err := acquireLock(ctx, e.config.LockFile, e.timeout+2*execWaitDelay)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.config.Command[0], e.config.Command[1:]...)
+	cmd.Dir = e.config.WorkingDir
+	cmd.Env = append(os.Environ(), e.buildEnv(event, n, text)...)
+	for key, value := range e.config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stdin = bytes.NewReader(stdin)
+	stderr := &tailBuffer{max: maxExecStderr}
+	cmd.Stderr = stderr
+	// Don't wait forever for children that inherited stderr
+	cmd.WaitDelay = execWaitDelay
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command timed out after %s", e.timeout)
+	} else {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.Exited() {
+			err = fmt.Errorf("command exited with code %d", exitErr.ExitCode())
+		} else {
+			err = fmt.Errorf("failed to run command: %w", err)
+		}
+	}
+	if output := strings.TrimSpace(stderr.String()); output != "" {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return err
+}
+
+// acquireLock creates the lock file at path, waiting while another process
+// holds it. A lock file older than stale is taken over. The returned
+// function releases the lock.
+func acquireLock(ctx context.Context, path string, stale time.Duration) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > stale {
+			os.Remove(path)
+			continue
+		}
+
+		select {
+		case <-time.After(lockPollInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for lock file %s: %w", path, ctx.Err())
+		}
+	}
+}
+
+// buildEnv returns the SSL_MONITOR_* environment of a notification
+func (e *ExecNotifier) buildEnv(event string, n Notification, text messageText) []string {
+	domainName := n.Domain.DisplayName()
+
+	env := map[string]string{
+		"EVENT":          event,
+		"HOST":           n.Domain.Host,
+		"FILE":           n.Domain.File,
+		"ALIAS":          n.Domain.Alias,
+		"PORT":           strconv.Itoa(n.Domain.Port),
+		"ENDPOINT":       n.Endpoint(),
+		"NAME":           domainName,
+		"DAYS_REMAINING": fmt.Sprintf("%.1f", n.DaysRemaining),
+		"EXPIRY":         n.Expiry.Format(time.RFC3339),
+		"THRESHOLD":      strconv.Itoa(n.Threshold),
+		"SEVERITY":       string(n.Severity()),
+		"GROUP":          n.Domain.Group,
+		"TAGS":           strings.Join(n.Domain.Tags, ","),
+		"MESSAGE":        text.titleOr(expirySummary(domainName, n.DaysRemaining)),
+	}
+
+	for key, value := range n.Domain.Labels {
+		env["LABEL_"+fieldName(key)] = value
+	}
+
+	vars := make([]string, 0, len(env))
+	for key, value := range env {
+		vars = append(vars, "SSL_MONITOR_"+key+"="+value)
+	}
+	return vars
+}
+
+// buildInput returns the stdin of the command: the rendered body template,
+// or the notification as JSON
+func (e *ExecNotifier) buildInput(event string, n Notification, text messageText) ([]byte, error) {
+	if text.Body != "" {
+		return []byte(text.Body), nil
+	}
+
+	input := map[string]interface{}{
+		"event":          event,
+		"domain":         n.Domain.Key(),
+		"port":           n.Domain.Port,
+		"file":           n.Domain.File,
+		"alias":          n.Domain.Alias,
+		"endpoint":       n.Endpoint(),
+		"name":           n.Domain.Name,
+		"days_remaining": n.DaysRemaining,
+		"expiry":         n.Expiry.Format(time.RFC3339),
+		"threshold":      n.Threshold,
+		"severity":       n.Severity(),
+		"group":          n.Domain.Group,
+		"tags":           n.Domain.Tags,
+		"labels":         n.Domain.Labels,
+		"certificate": map[string]interface{}{
+			"subject":       n.Certificate.Subject,
+			"issuer":        n.Certificate.Issuer,
+			"serial_number": n.Certificate.SerialNumber,
+			"not_before":    n.Certificate.NotBefore.Format(time.RFC3339),
+			"not_after":     n.Certificate.NotAfter.Format(time.RFC3339),
+			"dns_names":     n.Certificate.DNSNames,
+			"chain":         n.Certificate.Chain,
+		},
+		"message": text.titleOr(expirySummary(n.Domain.Key(), n.DaysRemaining)),
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal command input: %w", err)
+	}
+	return data, nil
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
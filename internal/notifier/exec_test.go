@@ -0,0 +1,152 @@
+package notifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestExecEnvironment(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	e, err := NewExecNotifier(config.ExecConfig{
+		Command: []string{"sh", "-c", `echo "$SSL_MONITOR_EVENT $SSL_MONITOR_ENDPOINT $SSL_MONITOR_LABEL_TEAM $SSL_MONITOR_LABEL_COST_CENTER $EXTRA" > "$OUT"`},
+		Env:     map[string]string{"EXTRA": "extra", "OUT": out},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Labels = map[string]string{"team": "payments", "cost.center": "42"}
+
+	for _, event := range []string{"trigger", "resolve"} {
+		send := e.Send
+		if event == "resolve" {
+			send = e.Resolve
+		}
+		if err := send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := event + " a.example.com:443 payments 42 extra\n"; string(got) != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ExecConfig
+		wantErr string
+	}{
+		{"exit code", config.ExecConfig{Command: []string{"sh", "-c", "echo broken >&2; exit 3"}}, "command exited with code 3: broken"},
+		{"timeout", config.ExecConfig{Command: []string{"sleep", "5"}, Timeout: 1}, "command timed out after 1s"},
+		{"missing", config.ExecConfig{Command: []string{filepath.Join(t.TempDir(), "missing")}}, "failed to run command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExecNotifier(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = e.Send(context.Background(), Notification{Domain: config.DomainConfig{Host: "a.example.com"}, Expiry: time.Now()})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// overlapCommand runs for a moment and creates dir/overlap when another
+// run of it is in progress at the same time
+func overlapCommand(dir string) []string {
+	return []string{"sh", "-c", `mkdir "$0/running" 2>/dev/null || touch "$0/overlap"; sleep 0.2; rmdir "$0/running" 2>/dev/null; true`, dir}
+}
+
+// sendAll sends a notification through each notifier at the same time
+func sendAll(t *testing.T, notifiers ...*ExecNotifier) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for _, e := range notifiers {
+		wg.Add(1)
+		go func(e *ExecNotifier) {
+			defer wg.Done()
+			if err := e.Send(context.Background(), testNotification()); err != nil {
+				t.Error(err)
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+func TestExecMaxConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExecNotifier(config.ExecConfig{Command: overlapCommand(dir)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendAll(t, e, e, e)
+	if _, err := os.Stat(filepath.Join(dir, "overlap")); err == nil {
+		t.Error("commands ran at the same time with max_concurrent 1")
+	}
+
+	if _, err := NewExecNotifier(config.ExecConfig{Command: []string{"true"}, MaxConcurrent: -1}); err == nil {
+		t.Error("negative max_concurrent was accepted")
+	}
+}
+
+func TestExecLockFile(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "exec.lock")
+	// Separate notifiers stand in for separate processes
+	var notifiers []*ExecNotifier
+	for i := 0; i < 3; i++ {
+		e, err := NewExecNotifier(config.ExecConfig{Command: overlapCommand(dir), LockFile: lock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		notifiers = append(notifiers, e)
+	}
+	sendAll(t, notifiers...)
+	if _, err := os.Stat(filepath.Join(dir, "overlap")); err == nil {
+		t.Error("commands of separate notifiers ran at the same time")
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestExecLockFileHeld(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), "exec.lock")
+	if err := os.WriteFile(lock, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewExecNotifier(config.ExecConfig{Command: []string{"true"}, LockFile: lock, Timeout: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := e.Send(ctx, testNotification()); err == nil || !strings.Contains(err.Error(), "waiting for lock file") {
+		t.Errorf("error = %v, want a wait for the lock", err)
+	}
+
+	// A lock older than the timeout was left by a process that died
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Send(context.Background(), testNotification()); err != nil {
+		t.Errorf("stale lock file was not taken over: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("googlechat", config.GoogleChatConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.GoogleChatConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewGoogleChatNotifier(cfg)
+	})
+}
+
+// GoogleChatNotifier sends card messages to a Google Chat space webhook
+type GoogleChatNotifier struct {
+	config    config.GoogleChatConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewGoogleChatNotifier creates a new Google Chat notifier
+func NewGoogleChatNotifier(cfg config.GoogleChatConfig) (*GoogleChatNotifier, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoogleChatNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// googleChatMessage represents the payload sent to Google Chat
+type googleChatMessage struct {
+	Text    string             `json:"text,omitempty"`
+	CardsV2 []googleChatCardV2 `json:"cardsV2,omitempty"`
+}
+
+// googleChatCardV2 wraps a card with its identifier
+type googleChatCardV2 struct {
+	CardID string         `json:"cardId"`
+	Card   googleChatCard `json:"card"`
+}
+
+// googleChatCard represents a Google Chat card
+type googleChatCard struct {
+	Header   googleChatHeader    `json:"header"`
+	Sections []googleChatSection `json:"sections"`
+}
+
+// googleChatHeader is the card header
+type googleChatHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+// googleChatSection groups card widgets
+type googleChatSection struct {
+	Widgets []googleChatWidget `json:"widgets"`
+}
+
+// googleChatWidget is a card widget; only decorated text is used
+type googleChatWidget struct {
+	DecoratedText *googleChatDecoratedText `json:"decoratedText,omitempty"`
+}
+
+// googleChatDecoratedText shows a label above a value
+type googleChatDecoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+	WrapText bool   `json:"wrapText,omitempty"`
+}
+
+// Send sends a notification to Google Chat
+func (g *GoogleChatNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := g.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := g.buildMessage(n, text)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Google Chat message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", g.config.WebhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (g *GoogleChatNotifier) Name() string {
+	return "Google Chat"
+}
+
+// httpClient returns the client used for requests
+func (g *GoogleChatNotifier) httpClient() *http.Client {
+	return g.client
+}
+
+// buildMessage constructs the Google Chat card message
+func (g *GoogleChatNotifier) buildMessage(n Notification, text messageText) googleChatMessage {
+	domainName := n.Domain.DisplayName()
+
+	color := n.Severity().HexColor()
+	widgets := []googleChatWidget{
+		{DecoratedText: &googleChatDecoratedText{
+			TopLabel: "Status",
+			Text:     fmt.Sprintf(`<font color="%s">%s</font>`, color, html.EscapeString(n.Severity().Action())),
+			WrapText: true,
+		}},
+	}
+	for _, field := range messageFields(n) {
+		widgets = append(widgets, googleChatWidget{DecoratedText: &googleChatDecoratedText{
+			TopLabel: field.Label,
+			Text:     html.EscapeString(field.Value),
+			WrapText: true,
+		}})
+	}
+
+	return googleChatMessage{
+		Text: text.bodyOr(expirySummary(domainName, n.DaysRemaining)),
+		CardsV2: []googleChatCardV2{
+			{
+				CardID: "ssl-certificate-expiry",
+				Card: googleChatCard{
+					Header: googleChatHeader{
+						Title:    text.titleOr("⚠️ SSL Certificate Expiry Alert"),
+						Subtitle: domainName,
+					},
+					Sections: []googleChatSection{{Widgets: widgets}},
+				},
+			},
+		},
+	}
+}
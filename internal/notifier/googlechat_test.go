@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestGoogleChatSend(t *testing.T) {
+	var message googleChatMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/json; charset=UTF-8" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	g, err := NewGoogleChatNotifier(config.GoogleChatConfig{WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Labels = map[string]string{"team": "<ops>"}
+	if err := g.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Text != "SSL certificate for a.example.com expires in 3.0 days" {
+		t.Errorf("text = %q", message.Text)
+	}
+	if len(message.CardsV2) != 1 {
+		t.Fatalf("got %d cards, want 1", len(message.CardsV2))
+	}
+	card := message.CardsV2[0]
+	if card.CardID != "ssl-certificate-expiry" || card.Card.Header.Subtitle != "a.example.com" {
+		t.Errorf("card = %+v", card)
+	}
+	widgets := map[string]string{}
+	for _, widget := range card.Card.Sections[0].Widgets {
+		widgets[widget.DecoratedText.TopLabel] = widget.DecoratedText.Text
+	}
+	if got := widgets["Host"]; got != "a.example.com:443" {
+		t.Errorf("Host widget = %q", got)
+	}
+	// Card text is HTML, so values are escaped
+	if got := widgets["Labels"]; got != "team=&lt;ops&gt;" {
+		t.Errorf("Labels widget = %q", got)
+	}
+	if got := widgets["Status"]; got == "" {
+		t.Error("the Status widget is missing")
+	}
+}
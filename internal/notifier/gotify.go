@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("gotify", config.GotifyConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.GotifyConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewGotifyNotifier(cfg)
+	})
+}
+
+// GotifyNotifier sends notifications to a Gotify server
+type GotifyNotifier struct {
+	config    config.GotifyConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewGotifyNotifier creates a new Gotify notifier
+func NewGotifyNotifier(cfg config.GotifyConfig) (*GotifyNotifier, error) {
+	if cfg.ServerURL == "" {
+		return nil, fmt.Errorf("server URL is required")
+	}
+	if cfg.AppToken == "" {
+		return nil, fmt.Errorf("application token is required")
+	}
+	cfg.ServerURL = strings.TrimRight(cfg.ServerURL, "/")
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GotifyNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// gotifyMessage represents the payload of a Gotify message
+type gotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// Send sends a notification to Gotify
+func (g *GotifyNotifier) Send(ctx context.Context, n Notification) error {
+	domainName := n.Domain.DisplayName()
+
+	text, err := g.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := gotifyMessage{
+		Title:    text.titleOr(expirySummary(domainName, n.DaysRemaining)),
+		Message:  text.bodyOr(plainTextMessage(n)),
+		Priority: gotifyPriority(n.Severity()),
+		Extras: map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/plain"},
+		},
+	}
+	if n.URL() != "" {
+		message.Extras["client::notification"] = map[string]interface{}{
+			"click": map[string]string{"url": n.URL()},
+		}
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Gotify message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", g.config.ServerURL+"/message", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.config.AppToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (g *GotifyNotifier) Name() string {
+	return "Gotify"
+}
+
+// httpClient returns the client used for requests
+func (g *GotifyNotifier) httpClient() *http.Client {
+	return g.client
+}
+
+// gotifyPriority maps a severity to a Gotify priority (0-10)
+func gotifyPriority(s Severity) int {
+	switch s {
+	case SeverityExpired:
+		return 10
+	case SeverityCritical:
+		return 8
+	case SeverityWarning:
+		return 5
+	default:
+		return 2
+	}
+}
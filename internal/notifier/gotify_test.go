@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestGotifySend(t *testing.T) {
+	var message gotifyMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/message" {
+			t.Errorf("path = %q, want /message", r.URL.Path)
+		}
+		if got := r.Header.Get("X-Gotify-Key"); got != "app-token" {
+			t.Errorf("X-Gotify-Key = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	g, err := NewGotifyNotifier(config.GotifyConfig{ServerURL: srv.URL + "/", AppToken: "app-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Priority != 8 {
+		t.Errorf("priority = %d, want 8", message.Priority)
+	}
+	click, _ := json.Marshal(message.Extras["client::notification"])
+	if string(click) != `{"click":{"url":"https://a.example.com"}}` {
+		t.Errorf("client::notification = %s", click)
+	}
+}
+
+func TestGotifyPriority(t *testing.T) {
+	tests := map[Severity]int{
+		SeverityExpired:  10,
+		SeverityCritical: 8,
+		SeverityWarning:  5,
+		SeverityInfo:     2,
+	}
+	for severity, want := range tests {
+		if got := gotifyPriority(severity); got != want {
+			t.Errorf("gotifyPriority(%s) = %d, want %d", severity, got, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("journald", config.JournaldConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.JournaldConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewJournaldNotifier(cfg)
+	})
+}
+
+// JournaldNotifier writes notifications to the systemd journal with the
+// alert details as structured fields
+type JournaldNotifier struct {
+	config    config.JournaldConfig
+	templates *messageTemplates
+}
+
+// NewJournaldNotifier creates a new journald notifier
+func NewJournaldNotifier(cfg config.JournaldConfig) (*JournaldNotifier, error) {
+	config.ApplyDefaults(&cfg)
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournaldNotifier{
+		config:    cfg,
+		templates: templates,
+	}, nil
+}
+
+// Send writes the notification to the journal
+func (j *JournaldNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := j.templates.render(n)
+	if err != nil {
+		return err
+	}
+	payload := encodeJournalFields(j.buildFields(n, text))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "unixgram", j.config.SocketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to journal: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(payload); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// Name returns the name of the notifier
+func (j *JournaldNotifier) Name() string {
+	return "journald"
+}
+
+// buildFields returns the journal fields of a notification
+func (j *JournaldNotifier) buildFields(n Notification, text messageText) map[string]string {
+	domainName := n.Domain.DisplayName()
+
+	fields := map[string]string{
+		"MESSAGE":           text.bodyOr(text.titleOr(expirySummary(domainName, n.DaysRemaining))),
+		"PRIORITY":          fmt.Sprintf("%d", syslogSeverity(n.Severity())),
+		"SYSLOG_IDENTIFIER": j.config.Identifier,
+		"ENDPOINT":          n.Endpoint(),
+		"DAYS_REMAINING":    fmt.Sprintf("%.1f", n.DaysRemaining),
+		"THRESHOLD":         fmt.Sprintf("%d", n.Threshold),
+		"SEVERITY":          string(n.Severity()),
+		"EXPIRY":            n.Expiry.UTC().Format(time.RFC3339),
+	}
+	if n.Domain.Group != "" {
+		fields["GROUP"] = n.Domain.Group
+	}
+	if len(n.Domain.Tags) > 0 {
+		fields["TAGS"] = strings.Join(n.Domain.Tags, ",")
+	}
+	for key, value := range n.Domain.Labels {
+		fields["LABEL_"+fieldName(key)] = value
+	}
+	return fields
+}
+
+// encodeJournalFields encodes fields in the journal's native protocol. Values
+// containing newlines are sent with an explicit length.
+func encodeJournalFields(fields map[string]string) []byte {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value := fields[key]
+		if !strings.Contains(value, "\n") {
+			buf.WriteString(key + "=" + value + "\n")
+			continue
+		}
+		buf.WriteString(key + "\n")
+		binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value + "\n")
+	}
+	return buf.Bytes()
+}
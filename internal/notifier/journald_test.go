@@ -0,0 +1,51 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestJournaldSend(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	j, err := NewJournaldNotifier(config.JournaldConfig{SocketPath: socket, Templates: config.TemplateConfig{Body: "line1\nline2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Labels = map[string]string{"team": "payments", "cost-center": "42"}
+	if err := j.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	k, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := string(buf[:k])
+	for _, want := range []string{
+		"ENDPOINT=a.example.com:443\n",
+		"LABEL_COST_CENTER=42\n",
+		"LABEL_TEAM=payments\n",
+		"MESSAGE\n\x0b\x00\x00\x00\x00\x00\x00\x00line1\nline2\n",
+		"SEVERITY=critical\n",
+		"SYSLOG_IDENTIFIER=ssl-cert-monitor\n",
+	} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("entry %q does not contain %q", entry, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("matrix", config.MatrixConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.MatrixConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewMatrixNotifier(cfg)
+	})
+}
+
+// MatrixNotifier sends room messages through the Matrix client-server API
+type MatrixNotifier struct {
+	config    config.MatrixConfig
+	client    *http.Client
+	txnID     atomic.Int64
+	templates *messageTemplates
+}
+
+// NewMatrixNotifier creates a new Matrix notifier
+func NewMatrixNotifier(cfg config.MatrixConfig) (*MatrixNotifier, error) {
+	if cfg.HomeserverURL == "" {
+		return nil, fmt.Errorf("homeserver URL is required")
+	}
+	if cfg.AccessToken == "" {
+		return nil, fmt.Errorf("access token is required")
+	}
+	if cfg.RoomID == "" {
+		return nil, fmt.Errorf("room ID is required")
+	}
+	config.ApplyDefaults(&cfg)
+	cfg.HomeserverURL = strings.TrimRight(cfg.HomeserverURL, "/")
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MatrixNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}
+	m.txnID.Store(time.Now().UnixNano())
+	return m, nil
+}
+
+// matrixMessage represents an m.room.message event
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Send sends a notification to the Matrix room
+func (m *MatrixNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := m.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := m.buildMessage(n, text)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Matrix message: %w", err)
+	}
+
+	// Transaction IDs make the request idempotent if it is retried
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/ssl-monitor-%d",
+		m.config.HomeserverURL, url.PathEscape(m.config.RoomID), m.txnID.Add(1))
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.config.AccessToken)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.ErrCode != "" {
+			return fmt.Errorf("unexpected status code: %d: %s: %s", resp.StatusCode, result.ErrCode, result.Error)
+		}
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (m *MatrixNotifier) Name() string {
+	return "Matrix"
+}
+
+// httpClient returns the client used for requests
+func (m *MatrixNotifier) httpClient() *http.Client {
+	return m.client
+}
+
+// buildMessage constructs the message with a plain text and an HTML body
+func (m *MatrixNotifier) buildMessage(n Notification, text messageText) matrixMessage {
+	var body, formatted strings.Builder
+
+	title := text.titleOr("⚠️ SSL Certificate Expiry Alert")
+	body.WriteString(title + "\n")
+	formatted.WriteString(fmt.Sprintf(`<p><strong><font data-mx-color="%s">%s</font></strong></p>`,
+		n.Severity().HexColor(), html.EscapeString(title)))
+
+	if text.Body != "" {
+		// A body template replaces the field list
+		body.WriteString(text.Body)
+		formatted.WriteString(strings.ReplaceAll(html.EscapeString(text.Body), "\n", "<br>"))
+	} else {
+		formatted.WriteString("<ul>")
+		for _, field := range messageFields(n) {
+			body.WriteString(fmt.Sprintf("%s: %s\n", field.Label, field.Value))
+			formatted.WriteString(fmt.Sprintf("<li><strong>%s:</strong> %s</li>",
+				html.EscapeString(field.Label), html.EscapeString(field.Value)))
+		}
+		formatted.WriteString("</ul>")
+	}
+
+	return matrixMessage{
+		MsgType:       m.config.MsgType,
+		Body:          strings.TrimSuffix(body.String(), "\n"),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	}
+}
@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestMatrixSend(t *testing.T) {
+	var paths []string
+	var message matrixMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		paths = append(paths, r.URL.EscapedPath())
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		io.WriteString(w, `{"event_id":"$abc"}`)
+	}))
+	defer srv.Close()
+
+	m, err := NewMatrixNotifier(config.MatrixConfig{HomeserverURL: srv.URL + "/", AccessToken: "token", RoomID: "!room:example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Send(context.Background(), testNotification()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each message is sent with a new transaction ID
+	prefix := "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/ssl-monitor-"
+	if len(paths) != 2 || !strings.HasPrefix(paths[0], prefix) || !strings.HasPrefix(paths[1], prefix) {
+		t.Fatalf("paths = %q, want the prefix %q", paths, prefix)
+	}
+	if paths[0] == paths[1] {
+		t.Errorf("both messages used the transaction ID of %q", paths[0])
+	}
+
+	if message.MsgType != "m.notice" || message.Format != "org.matrix.custom.html" {
+		t.Errorf("msgtype and format = %q %q", message.MsgType, message.Format)
+	}
+	if !strings.Contains(message.Body, "Host: a.example.com:443") {
+		t.Errorf("body = %q", message.Body)
+	}
+	if !strings.Contains(message.FormattedBody, "<li><strong>Days Remaining:</strong> 3.0</li>") {
+		t.Errorf("formatted body = %q", message.FormattedBody)
+	}
+}
+
+func TestMatrixError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"errcode":"M_FORBIDDEN","error":"User not in room"}`)
+	}))
+	defer srv.Close()
+
+	m, err := NewMatrixNotifier(config.MatrixConfig{HomeserverURL: srv.URL, AccessToken: "token", RoomID: "!room:example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "403: M_FORBIDDEN: User not in room") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("mattermost", config.MattermostConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.MattermostConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewMattermostNotifier(cfg)
+	})
+}
+
+// MattermostNotifier sends notifications to Mattermost via incoming webhook
+type MattermostNotifier struct {
+	config    config.MattermostConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewMattermostNotifier creates a new Mattermost notifier
+func NewMattermostNotifier(cfg config.MattermostConfig) (*MattermostNotifier, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MattermostNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// mattermostMessage represents the payload sent to Mattermost
+type mattermostMessage struct {
+	Text        string                 `json:"text,omitempty"`
+	Channel     string                 `json:"channel,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	IconEmoji   string                 `json:"icon_emoji,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments,omitempty"`
+}
+
+// mattermostAttachment represents a message attachment
+type mattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	Fields   []mattermostField `json:"fields,omitempty"`
+	Footer   string            `json:"footer,omitempty"`
+}
+
+// mattermostField represents a field within an attachment
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Send sends a notification to Mattermost
+func (m *MattermostNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := m.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := m.buildMessage(n, text)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Mattermost message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.config.WebhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (m *MattermostNotifier) Name() string {
+	return "Mattermost"
+}
+
+// httpClient returns the client used for requests
+func (m *MattermostNotifier) httpClient() *http.Client {
+	return m.client
+}
+
+// buildMessage constructs the Mattermost message
+func (m *MattermostNotifier) buildMessage(n Notification, text messageText) mattermostMessage {
+	domainName := n.Domain.DisplayName()
+
+	var fields []mattermostField
+	for _, field := range messageFields(n) {
+		fields = append(fields, mattermostField{Title: field.Label, Value: field.Value, Short: true})
+	}
+
+	return mattermostMessage{
+		Channel:   m.config.Channel,
+		Username:  m.config.Username,
+		IconURL:   m.config.IconURL,
+		IconEmoji: m.config.IconEmoji,
+		Attachments: []mattermostAttachment{
+			{
+				Fallback: expirySummary(domainName, n.DaysRemaining),
+				Color:    n.Severity().HexColor(),
+				Title:    text.titleOr("⚠️ SSL Certificate Expiry Alert"),
+				Text:     text.bodyOr(expiryHeadline("**"+domainName+"**", n)),
+				Fields:   fields,
+				Footer:   "SSL Certificate Monitor",
+			},
+		},
+	}
+}
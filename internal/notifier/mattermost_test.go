@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestMattermostSend(t *testing.T) {
+	var message mattermostMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	m, err := NewMattermostNotifier(config.MattermostConfig{WebhookURL: srv.URL, Channel: "alerts", Username: "monitor", IconEmoji: ":lock:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Channel != "alerts" || message.Username != "monitor" || message.IconEmoji != ":lock:" {
+		t.Errorf("message = %+v, want the configured channel, username and icon", message)
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(message.Attachments))
+	}
+	attachment := message.Attachments[0]
+	if attachment.Color != SeverityCritical.HexColor() {
+		t.Errorf("color = %q, want %q", attachment.Color, SeverityCritical.HexColor())
+	}
+	if attachment.Fallback != "SSL certificate for a.example.com expires in 3.0 days" {
+		t.Errorf("fallback = %q", attachment.Fallback)
+	}
+	if attachment.Text != "Certificate for **a.example.com** is expiring soon!" {
+		t.Errorf("text = %q", attachment.Text)
+	}
+	if len(attachment.Fields) == 0 || attachment.Fields[0].Title != "Domain" || !attachment.Fields[0].Short {
+		t.Errorf("fields = %+v", attachment.Fields)
+	}
+}
+
+func TestMattermostError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	m, err := NewMattermostNotifier(config.MattermostConfig{WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), testNotification()); err == nil {
+		t.Error("expected an error for status 400")
+	}
+}
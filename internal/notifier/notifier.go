@@ -2,7 +2,11 @@ package notifier
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/config"
@@ -14,6 +18,125 @@ type Notification struct {
 	DaysRemaining float64
 	Expiry        time.Time
 	Threshold     int
+	Certificate   config.CertificateInfo
+}
+
+// Endpoint returns the host:port or certificate file the notification is
+// about
+func (n Notification) Endpoint() string {
+	return n.Domain.Endpoint()
+}
+
+// URL returns an https URL for the affected host, used as a click-through
+// link. It is empty for certificate files.
+func (n Notification) URL() string {
+	if n.Domain.File != "" {
+		return ""
+	}
+	if n.Domain.Port == 443 || n.Domain.Port == 0 {
+		return "https://" + n.Domain.Host
+	}
+	return fmt.Sprintf("https://%s:%d", n.Domain.Host, n.Domain.Port)
+}
+
+// expirySummary returns the one-line summary of a notification about name
+func expirySummary(name string, daysRemaining float64) string {
+	if daysRemaining <= 0 {
+		return fmt.Sprintf("SSL certificate for %s has expired", name)
+	}
+	return fmt.Sprintf("SSL certificate for %s expires in %.1f days", name, daysRemaining)
+}
+
+// expiryHeadline says that the certificate for name is expiring soon or has
+// expired. Name may already be formatted for the message, e.g. in bold.
+func expiryHeadline(name string, n Notification) string {
+	if n.Severity() == SeverityExpired {
+		return fmt.Sprintf("Certificate for %s has expired!", name)
+	}
+	return fmt.Sprintf("Certificate for %s is expiring soon!", name)
+}
+
+// Severity returns the severity of the notification
+func (n Notification) Severity() Severity {
+	return SeverityFor(n.DaysRemaining)
+}
+
+// messageField is a label/value pair describing a notification
+type messageField struct {
+	Label string
+	Value string
+}
+
+// messageFields returns the standard fields shown in chat messages
+func messageFields(n Notification) []messageField {
+	domainName := n.Domain.DisplayName()
+
+	endpointLabel := "Host"
+	if n.Domain.File != "" {
+		endpointLabel = "File"
+	}
+	fields := []messageField{
+		{Label: "Domain", Value: domainName},
+		{Label: endpointLabel, Value: n.Endpoint()},
+		{Label: "Days Remaining", Value: fmt.Sprintf("%.1f", n.DaysRemaining)},
+		{Label: "Expiry Date", Value: n.Expiry.Format("2006-01-02 15:04:05 MST")},
+		{Label: "Threshold", Value: fmt.Sprintf("%d days", n.Threshold)},
+		{Label: "Check Time", Value: time.Now().Format("2006-01-02 15:04:05 MST")},
+	}
+	if n.Domain.Group != "" {
+		fields = append(fields, messageField{Label: "Group", Value: n.Domain.Group})
+	}
+	if len(n.Domain.Tags) > 0 {
+		fields = append(fields, messageField{Label: "Tags", Value: strings.Join(n.Domain.Tags, ", ")})
+	}
+	if len(n.Domain.Labels) > 0 {
+		fields = append(fields, messageField{Label: "Labels", Value: formatLabels(n.Domain.Labels)})
+	}
+	return fields
+}
+
+// plainTextMessage renders the standard fields as "Label: value" lines
+func plainTextMessage(n Notification) string {
+	var sb strings.Builder
+	for _, field := range messageFields(n) {
+		sb.WriteString(field.Label + ": " + field.Value + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatLabels renders labels as a sorted "key=value, ..." list
+func formatLabels(labels map[string]string) string {
+	keys := sortedKeys(labels)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// sortedKeys returns the keys of labels in order
+func sortedKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldName turns a label key into an upper-case name of letters, digits
+// and underscores, as used for environment variables and journal fields
+func fieldName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
 }
 
 // Notifier defines the interface for sending notifications
@@ -22,24 +145,60 @@ type Notifier interface {
 	Name() string
 }
 
+// Resolver is implemented by notifiers that keep alerts open until the
+// certificate is renewed, such as incident management tools
+type Resolver interface {
+	Resolve(ctx context.Context, n Notification) error
+}
+
+// FailureAlerter is implemented by notifiers that also open an alert when
+// a certificate cannot be checked, resolved once the check succeeds again
+type FailureAlerter interface {
+	SendFailure(ctx context.Context, n Notification, checkErr error) error
+	ResolveFailure(ctx context.Context, n Notification) error
+}
+
 // Manager coordinates multiple notifiers
 type Manager struct {
-	notifiers []Notifier
+	notifiers []namedNotifier
 }
 
-// NewManager creates a new notification manager
+// namedNotifier is a notifier registered under its configured name
+type namedNotifier struct {
+	name string
+	Notifier
+}
+
+// NewManager creates a new notification manager. Each notifier is registered
+// under its own Name.
 func NewManager(notifiers ...Notifier) *Manager {
-	return &Manager{
-		notifiers: notifiers,
+	m := &Manager{}
+	for _, n := range notifiers {
+		m.Add(n.Name(), n)
 	}
+	return m
+}
+
+// Add registers a notifier under the given name
+func (m *Manager) Add(name string, n Notifier) {
+	m.notifiers = append(m.notifiers, namedNotifier{name: name, Notifier: n})
 }
 
 // Send sends a notification through all registered notifiers
 func (m *Manager) Send(ctx context.Context, n Notification) error {
+	return m.SendTo(ctx, nil, n)
+}
+
+// SendTo sends a notification through the named notifiers only.
+// An empty list of names sends through all registered notifiers.
+func (m *Manager) SendTo(ctx context.Context, names []string, n Notification) error {
 	var errs []error
 	for _, notifier := range m.notifiers {
+		if len(names) > 0 && !containsName(names, notifier.name) {
+			continue
+		}
 		if err := notifier.Send(ctx, n); err != nil {
-			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.name, err))
 		}
 	}
 	if len(errs) > 0 {
@@ -48,41 +207,137 @@ func (m *Manager) Send(ctx context.Context, n Notification) error {
 	return nil
 }
 
-// BuildNotifiers creates notifiers based on configuration
-func BuildNotifiers(cfg *config.Config) (*Manager, error) {
-	var notifiers []Notifier
+// ResolveTo resolves a previously sent notification through the named
+// notifiers that implement Resolver. An empty list of names uses all of them.
+func (m *Manager) ResolveTo(ctx context.Context, names []string, n Notification) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if len(names) > 0 && !containsName(names, notifier.name) {
+			continue
+		}
+		resolver, ok := notifier.Notifier.(Resolver)
+		if !ok {
+			continue
+		}
+		if err := resolver.Resolve(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to resolve notifications: %v", errs)
+	}
+	return nil
+}
 
-	if cfg.Notifications.Slack.Enabled {
-		slack, err := NewSlackNotifier(cfg.Notifications.Slack)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Slack notifier: %w", err)
+// SendFailureTo reports a failed check through the named notifiers that
+// implement FailureAlerter, all of them for an empty list of names, and
+// returns the names of those it was sent to. Only the domain of the
+// notification is set.
+func (m *Manager) SendFailureTo(ctx context.Context, names []string, n Notification, checkErr error) ([]string, error) {
+	var sent []string
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if len(names) > 0 && !containsName(names, notifier.name) {
+			continue
+		}
+		alerter, ok := notifier.Notifier.(FailureAlerter)
+		if !ok {
+			continue
 		}
-		notifiers = append(notifiers, slack)
+		if err := alerter.SendFailure(ctx, n, checkErr); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.name, err))
+			continue
+		}
+		sent = append(sent, notifier.name)
+	}
+	if len(errs) > 0 {
+		return sent, fmt.Errorf("failed to send check failure alerts: %v", errs)
 	}
+	return sent, nil
+}
 
-	if cfg.Notifications.Email.Enabled {
-		email, err := NewEmailNotifier(cfg.Notifications.Email)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create email notifier: %w", err)
+// ResolveFailureTo resolves a failed check alert through the named notifiers
+// that implement FailureAlerter
+func (m *Manager) ResolveFailureTo(ctx context.Context, names []string, n Notification) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if !containsName(names, notifier.name) {
+			continue
+		}
+		alerter, ok := notifier.Notifier.(FailureAlerter)
+		if !ok {
+			continue
 		}
-		notifiers = append(notifiers, email)
+		if err := alerter.ResolveFailure(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to resolve check failure alerts: %v", errs)
 	}
+	return nil
+}
 
-	if cfg.Notifications.Webhook.Enabled {
-		webhook, err := NewWebhookNotifier(cfg.Notifications.Webhook)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create webhook notifier: %w", err)
+// Has reports whether a notifier with the given name is registered
+func (m *Manager) Has(name string) bool {
+	for _, notifier := range m.notifiers {
+		if strings.EqualFold(notifier.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsName reports whether name is in names, ignoring case
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
 		}
-		notifiers = append(notifiers, webhook)
 	}
+	return false
+}
 
-	if cfg.Notifications.Discord.Enabled {
-		discord, err := NewDiscordNotifier(cfg.Notifications.Discord)
+// BuildNotifiers creates the enabled notifiers defined in the configuration.
+// The type and settings of disabled notifiers are checked too, so they can
+// be enabled later without surprises. All problems are reported together.
+func BuildNotifiers(cfg *config.Config, env Env) (*Manager, error) {
+	m := NewManager()
+
+	var errs []error
+	for _, def := range cfg.Notifications {
+		factory, ok := registry[def.Type]
+		if !ok {
+			errs = append(errs, withSource(def.Source, fmt.Errorf("notifier %q has unknown type %q (known types: %s)", def.Name, def.Type, strings.Join(Types(), ", "))))
+			continue
+		}
+		if !def.Enabled {
+			settings := reflect.New(settingsTypes[def.Type]).Interface()
+			if err := def.Decode(settings); err != nil {
+				errs = append(errs, withSource(def.Source, err))
+			}
+			continue
+		}
+
+		n, err := factory(def, env)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create Discord notifier: %w", err)
+			errs = append(errs, withSource(def.Source, fmt.Errorf("failed to create %s notifier %q: %w", def.Type, def.Name, err)))
+			continue
 		}
-		notifiers = append(notifiers, discord)
+		env.configureClient(n)
+		m.Add(def.Name, n)
 	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return m, nil
+}
 
-	return NewManager(notifiers...), nil
-}
\ No newline at end of file
+// withSource prefixes an error with the file:line it was found at, if known
+func withSource(source string, err error) error {
+	if source == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", source, err)
+}
@@ -0,0 +1,51 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// testNotification returns a critical notification for a.example.com
+func testNotification() Notification {
+	return Notification{
+		Domain:        config.DomainConfig{Host: "a.example.com", Port: 443},
+		DaysRemaining: 3,
+		Expiry:        time.Now(),
+		Threshold:     7,
+	}
+}
+
+func TestExpiredWording(t *testing.T) {
+	n := testNotification()
+	n.DaysRemaining = -2
+	n.Threshold = 0
+
+	html, err := (&EmailNotifier{}).buildHTMLBody(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := buildSlackBlocks(n, messageText{})
+	messages := map[string]string{
+		"summary":  expirySummary("a.example.com", n.DaysRemaining),
+		"headline": expiryHeadline("a.example.com", n),
+		"opsgenie": opsgenieDescription(n),
+		"email":    html,
+		"slack":    blocks[1].Text.Text,
+	}
+	for name, message := range messages {
+		if !strings.Contains(message, "expired") {
+			t.Errorf("%s does not say the certificate has expired: %q", name, message)
+		}
+		if strings.Contains(message, "expires in") || strings.Contains(message, "soon") || strings.Contains(message, "-2.0 days") {
+			t.Errorf("%s describes an expired certificate as expiring: %q", name, message)
+		}
+	}
+
+	n.DaysRemaining = 3
+	if got, want := expiryHeadline("**a.example.com**", n), "Certificate for **a.example.com** is expiring soon!"; got != want {
+		t.Errorf("headline = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("ntfy", config.NtfyConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.NtfyConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewNtfyNotifier(cfg)
+	})
+}
+
+// NtfyNotifier publishes notifications to an ntfy topic
+type NtfyNotifier struct {
+	config    config.NtfyConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewNtfyNotifier creates a new ntfy notifier
+func NewNtfyNotifier(cfg config.NtfyConfig) (*NtfyNotifier, error) {
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	config.ApplyDefaults(&cfg)
+	cfg.ServerURL = strings.TrimRight(cfg.ServerURL, "/")
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NtfyNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// ntfyMessage represents a JSON publish request
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+}
+
+// Send publishes a notification to the topic
+func (t *NtfyNotifier) Send(ctx context.Context, n Notification) error {
+	domainName := n.Domain.DisplayName()
+
+	text, err := t.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := ntfyMessage{
+		Topic:    t.config.Topic,
+		Title:    text.titleOr(expirySummary(domainName, n.DaysRemaining)),
+		Message:  text.bodyOr(plainTextMessage(n)),
+		Priority: ntfyPriority(n.Severity()),
+		Tags:     append([]string{"lock", string(n.Severity())}, n.Domain.Tags...),
+		Click:    n.URL(),
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ntfy message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.config.ServerURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.config.Token)
+	} else if t.config.Username != "" {
+		req.SetBasicAuth(t.config.Username, t.config.Password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (t *NtfyNotifier) Name() string {
+	return "ntfy"
+}
+
+// httpClient returns the client used for requests
+func (t *NtfyNotifier) httpClient() *http.Client {
+	return t.client
+}
+
+// ntfyPriority maps a severity to an ntfy priority (1 = min, 5 = max)
+func ntfyPriority(s Severity) int {
+	switch s {
+	case SeverityExpired:
+		return 5
+	case SeverityCritical:
+		return 4
+	case SeverityWarning:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestNtfyAuthentication(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.NtfyConfig
+		want string
+	}{
+		{"none", config.NtfyConfig{}, ""},
+		{"token", config.NtfyConfig{Token: "tk_abc", Username: "ignored", Password: "ignored"}, "Bearer tk_abc"},
+		{"basic", config.NtfyConfig{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization string
+			var message ntfyMessage
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+					t.Errorf("decoding message: %v", err)
+				}
+			}))
+			defer srv.Close()
+
+			cfg := tt.cfg
+			cfg.ServerURL = srv.URL + "/"
+			cfg.Topic = "certs"
+			nt, err := NewNtfyNotifier(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := nt.Send(context.Background(), testNotification()); err != nil {
+				t.Fatal(err)
+			}
+			if authorization != tt.want {
+				t.Errorf("Authorization = %q, want %q", authorization, tt.want)
+			}
+			if message.Topic != "certs" || message.Priority != 4 || message.Click != "https://a.example.com" {
+				t.Errorf("message = %+v", message)
+			}
+		})
+	}
+}
+
+func TestNtfyPriority(t *testing.T) {
+	tests := map[Severity]int{
+		SeverityExpired:  5,
+		SeverityCritical: 4,
+		SeverityWarning:  3,
+		SeverityInfo:     2,
+	}
+	for severity, want := range tests {
+		if got := ntfyPriority(severity); got != want {
+			t.Errorf("ntfyPriority(%s) = %d, want %d", severity, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// tokenExpiryMargin renews tokens this long before they expire
+const tokenExpiryMargin = 30 * time.Second
+
+// clientCredentials fetches and caches access tokens with the OAuth2 client
+// credentials grant
+type clientCredentials struct {
+	config config.WebhookOAuth2Config
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// newClientCredentials validates the OAuth2 settings
+func newClientCredentials(cfg config.WebhookOAuth2Config, client *http.Client) (*clientCredentials, error) {
+	if cfg.TokenURL == "" {
+		return nil, fmt.Errorf("oauth2 token_url is required")
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("oauth2 client_id is required")
+	}
+	return &clientCredentials{config: cfg, client: client}, nil
+}
+
+// Token returns a cached access token, fetching a new one when there is none
+// or it is about to expire
+func (c *clientCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expiry.IsZero() || time.Now().Add(tokenExpiryMargin).Before(c.expiry)) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiry = time.Time{}
+	if expiresIn > 0 {
+		c.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return c.token, nil
+}
+
+// Invalidate drops the cached token, e.g. after the receiver rejected it
+func (c *clientCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// fetch requests a new token from the token endpoint
+func (c *clientCredentials) fetch(ctx context.Context) (string, int64, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.config.Scopes, " "))
+	}
+	for key, value := range c.config.EndpointParams {
+		form.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// RFC 6749 section 2.3.1 requires the credentials to be form-encoded
+	req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return "", 0, fmt.Errorf("token endpoint returned status code %d", resp.StatusCode)
+		}
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if result.Error != "" {
+		return "", 0, fmt.Errorf("token endpoint returned %s: %s", result.Error, result.ErrorDescription)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("token endpoint returned status code %d", resp.StatusCode)
+	}
+	if result.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response has no access_token")
+	}
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", result.TokenType)
+	}
+
+	return result.AccessToken, result.ExpiresIn, nil
+}
@@ -0,0 +1,214 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// opsgenieURLs maps Opsgenie regions to their API base URLs
+var opsgenieURLs = map[string]string{
+	"us": "https://api.opsgenie.com",
+	"eu": "https://api.eu.opsgenie.com",
+}
+
+func init() {
+	Register("opsgenie", config.OpsgenieConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.OpsgenieConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewOpsgenieNotifier(cfg)
+	})
+}
+
+// OpsgenieNotifier creates and closes alerts through the Opsgenie Alert API
+type OpsgenieNotifier struct {
+	config    config.OpsgenieConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewOpsgenieNotifier creates a new Opsgenie notifier
+func NewOpsgenieNotifier(cfg config.OpsgenieConfig) (*OpsgenieNotifier, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	config.ApplyDefaults(&cfg)
+	if cfg.BaseURL == "" {
+		baseURL, ok := opsgenieURLs[strings.ToLower(cfg.Region)]
+		if !ok {
+			return nil, fmt.Errorf("unknown region %q", cfg.Region)
+		}
+		cfg.BaseURL = baseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpsgenieNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// opsgenieAlert represents the payload for creating an alert
+type opsgenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []opsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source,omitempty"`
+	Priority    string              `json:"priority"`
+}
+
+// opsgenieResponder identifies a team responsible for an alert
+type opsgenieResponder struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// opsgenieClose represents the payload for closing an alert
+type opsgenieClose struct {
+	Source string `json:"source,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Send creates an alert for the endpoint and threshold. Opsgenie
+// deduplicates open alerts with the same alias.
+func (o *OpsgenieNotifier) Send(ctx context.Context, n Notification) error {
+	domainName := n.Domain.DisplayName()
+
+	details := map[string]string{
+		"domain":         domainName,
+		"endpoint":       n.Endpoint(),
+		"days_remaining": fmt.Sprintf("%.1f", n.DaysRemaining),
+		"expiry":         n.Expiry.Format(time.RFC3339),
+		"threshold":      fmt.Sprintf("%d", n.Threshold),
+		"severity":       string(n.Severity()),
+	}
+	if n.Domain.Group != "" {
+		details["group"] = n.Domain.Group
+	}
+	for key, value := range n.Domain.Labels {
+		details["label."+key] = value
+	}
+
+	text, err := o.templates.render(n)
+	if err != nil {
+		return err
+	}
+	alert := opsgenieAlert{
+		Message:     text.titleOr(expirySummary(domainName, n.DaysRemaining)),
+		Alias:       opsgenieAlias(n),
+		Description: text.bodyOr(opsgenieDescription(n)),
+		Tags:        append(append([]string{}, o.config.Tags...), n.Domain.Tags...),
+		Details:     details,
+		Entity:      n.Endpoint(),
+		Source:      "SSL Certificate Monitor",
+		Priority:    opsgeniePriority(n.DaysRemaining),
+	}
+	if o.config.Team != "" {
+		alert.Responders = []opsgenieResponder{{Name: o.config.Team, Type: "team"}}
+	}
+
+	return o.post(ctx, o.config.BaseURL+"/v2/alerts", alert)
+}
+
+// Resolve closes the alert for the endpoint and threshold
+func (o *OpsgenieNotifier) Resolve(ctx context.Context, n Notification) error {
+	endpoint := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", o.config.BaseURL, url.PathEscape(opsgenieAlias(n)))
+	return o.post(ctx, endpoint, opsgenieClose{
+		Source: "SSL Certificate Monitor",
+		Note:   fmt.Sprintf("Certificate renewed, now expires on %s", n.Expiry.Format("2006-01-02")),
+	})
+}
+
+// Name returns the name of the notifier
+func (o *OpsgenieNotifier) Name() string {
+	return "Opsgenie"
+}
+
+// httpClient returns the client used for requests
+func (o *OpsgenieNotifier) httpClient() *http.Client {
+	return o.client
+}
+
+// post sends a request to the Alert API. Opsgenie processes requests
+// asynchronously and answers 202 Accepted on success.
+func (o *OpsgenieNotifier) post(ctx context.Context, endpoint string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Opsgenie request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.config.APIKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Message != "" {
+			return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, result.Message)
+		}
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// opsgenieAlias returns an alias that is stable per endpoint and threshold,
+// so repeated reminders are deduplicated into the same alert
+func opsgenieAlias(n Notification) string {
+	return fmt.Sprintf("ssl-cert-monitor/%s/%d", n.Endpoint(), n.Threshold)
+}
+
+// opsgeniePriority maps days remaining to an Opsgenie priority
+func opsgeniePriority(daysRemaining float64) string {
+	switch {
+	case daysRemaining <= 0:
+		return "P1"
+	case daysRemaining <= 3:
+		return "P2"
+	case daysRemaining <= 7:
+		return "P3"
+	case daysRemaining <= 30:
+		return "P4"
+	default:
+		return "P5"
+	}
+}
+
+// opsgenieDescription describes the alert, including the expiry date
+func opsgenieDescription(n Notification) string {
+	expiry := n.Expiry.Format("2006-01-02 15:04:05 MST")
+	if n.Severity() == SeverityExpired {
+		return fmt.Sprintf("The certificate for %s expired on %s.", n.Endpoint(), expiry)
+	}
+	return fmt.Sprintf("The certificate for %s expires on %s (threshold: %d days).", n.Endpoint(), expiry, n.Threshold)
+}
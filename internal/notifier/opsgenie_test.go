@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// opsgenieRequest is a request received by the Alert API stand-in
+type opsgenieRequest struct {
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+func TestOpsgenieCreateAndClose(t *testing.T) {
+	var requests []opsgenieRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := opsgenieRequest{path: r.URL.RequestURI(), authorization: r.Header.Get("Authorization")}
+		if err := json.NewDecoder(r.Body).Decode(&req.body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	o, err := NewOpsgenieNotifier(config.OpsgenieConfig{APIKey: "key", BaseURL: srv.URL + "/", Team: "platform", Tags: []string{"tls"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Tags = []string{"web"}
+	n.Domain.Labels = map[string]string{"team": "payments"}
+	if err := o.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Resolve(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	create, closeAlert := requests[0], requests[1]
+	for _, req := range requests {
+		if req.authorization != "GenieKey key" {
+			t.Errorf("Authorization = %q", req.authorization)
+		}
+	}
+	if create.path != "/v2/alerts" {
+		t.Errorf("create path = %q", create.path)
+	}
+	if got := create.body["alias"]; got != "ssl-cert-monitor/a.example.com:443/7" {
+		t.Errorf("alias = %v", got)
+	}
+	if got := create.body["priority"]; got != "P2" {
+		t.Errorf("priority = %v, want P2", got)
+	}
+	if got, _ := json.Marshal(create.body["tags"]); string(got) != `["tls","web"]` {
+		t.Errorf("tags = %s", got)
+	}
+	if got, _ := json.Marshal(create.body["responders"]); string(got) != `[{"name":"platform","type":"team"}]` {
+		t.Errorf("responders = %s", got)
+	}
+	details, _ := create.body["details"].(map[string]interface{})
+	if details["label.team"] != "payments" || details["threshold"] != "7" {
+		t.Errorf("details = %v", details)
+	}
+
+	// The close call addresses the alert by the alias it was created with
+	if want := "/v2/alerts/ssl-cert-monitor%2Fa.example.com:443%2F7/close?identifierType=alias"; closeAlert.path != want {
+		t.Errorf("close path = %q, want %q", closeAlert.path, want)
+	}
+	if note, _ := closeAlert.body["note"].(string); !strings.HasPrefix(note, "Certificate renewed") {
+		t.Errorf("close note = %q", note)
+	}
+}
+
+func TestOpsgenieRegions(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{"", "https://api.opsgenie.com"},
+		{"us", "https://api.opsgenie.com"},
+		{"eu", "https://api.eu.opsgenie.com"},
+		{"EU", "https://api.eu.opsgenie.com"},
+	}
+	for _, tt := range tests {
+		o, err := NewOpsgenieNotifier(config.OpsgenieConfig{APIKey: "key", Region: tt.region})
+		if err != nil {
+			t.Fatalf("region %q: %v", tt.region, err)
+		}
+		if o.config.BaseURL != tt.want {
+			t.Errorf("region %q: base URL = %q, want %q", tt.region, o.config.BaseURL, tt.want)
+		}
+	}
+
+	if _, err := NewOpsgenieNotifier(config.OpsgenieConfig{APIKey: "key", Region: "apac"}); err == nil || !strings.Contains(err.Error(), `unknown region "apac"`) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestOpsgeniePriority(t *testing.T) {
+	tests := []struct {
+		days float64
+		want string
+	}{
+		{-1, "P1"},
+		{0, "P1"},
+		{2.5, "P2"},
+		{3, "P2"},
+		{7, "P3"},
+		{14, "P4"},
+		{30, "P4"},
+		{45, "P5"},
+	}
+	for _, tt := range tests {
+		if got := opsgeniePriority(tt.days); got != tt.want {
+			t.Errorf("opsgeniePriority(%v) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestOpsgenieError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"message":"Key format is not valid!","took":0.001,"requestId":"abc"}`)
+	}))
+	defer srv.Close()
+
+	o, err := NewOpsgenieNotifier(config.OpsgenieConfig{APIKey: "key", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = o.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "401: Key format is not valid!") {
+		t.Errorf("error = %v", err)
+	}
+}
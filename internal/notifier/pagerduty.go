@@ -0,0 +1,219 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("pagerduty", config.PagerDutyConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.PagerDutyConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewPagerDutyNotifier(cfg)
+	})
+}
+
+// PagerDutyNotifier sends trigger and resolve events to the PagerDuty
+// Events API v2
+type PagerDutyNotifier struct {
+	config    config.PagerDutyConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier
+func NewPagerDutyNotifier(cfg config.PagerDutyConfig) (*PagerDutyNotifier, error) {
+	if cfg.RoutingKey == "" {
+		return nil, fmt.Errorf("routing key is required")
+	}
+	config.ApplyDefaults(&cfg)
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PagerDutyNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// pagerDutyEvent represents an Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+}
+
+// pagerDutyPayload holds the details of a trigger event
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// Send triggers (or updates) the alert for the endpoint and threshold
+func (p *PagerDutyNotifier) Send(ctx context.Context, n Notification) error {
+	domainName := n.Domain.DisplayName()
+
+	text, err := p.templates.render(n)
+	if err != nil {
+		return err
+	}
+	event := pagerDutyEvent{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    pagerDutyDedupKey(n),
+		Client:      "SSL Certificate Monitor",
+		Payload: &pagerDutyPayload{
+			Summary:   text.titleOr(expirySummary(domainName, n.DaysRemaining)),
+			Source:    n.Endpoint(),
+			Severity:  pagerDutySeverity(n.Severity()),
+			Timestamp: time.Now().Format(time.RFC3339),
+			Component: n.Domain.Key(),
+			Group:     n.Domain.Group,
+			Class:     "ssl-certificate-expiry",
+			CustomDetails: map[string]interface{}{
+				"domain":         domainName,
+				"days_remaining": n.DaysRemaining,
+				"expiry":         n.Expiry.Format(time.RFC3339),
+				"threshold":      n.Threshold,
+				"tags":           n.Domain.Tags,
+				"labels":         n.Domain.Labels,
+			},
+		},
+	}
+	if text.Body != "" {
+		event.Payload.CustomDetails["message"] = text.Body
+	}
+
+	return p.post(ctx, event)
+}
+
+// Resolve resolves the alert for the endpoint and threshold
+func (p *PagerDutyNotifier) Resolve(ctx context.Context, n Notification) error {
+	return p.post(ctx, pagerDutyEvent{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    pagerDutyDedupKey(n),
+	})
+}
+
+// SendFailure triggers an alert for an endpoint that could not be checked
+func (p *PagerDutyNotifier) SendFailure(ctx context.Context, n Notification, checkErr error) error {
+	domainName := n.Domain.DisplayName()
+
+	return p.post(ctx, pagerDutyEvent{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    pagerDutyFailureDedupKey(n),
+		Client:      "SSL Certificate Monitor",
+		Payload: &pagerDutyPayload{
+			Summary:   fmt.Sprintf("SSL certificate check for %s failed", domainName),
+			Source:    n.Endpoint(),
+			Severity:  "error",
+			Timestamp: time.Now().Format(time.RFC3339),
+			Component: n.Domain.Key(),
+			Group:     n.Domain.Group,
+			Class:     "ssl-certificate-check",
+			CustomDetails: map[string]interface{}{
+				"domain": domainName,
+				"error":  checkErr.Error(),
+				"tags":   n.Domain.Tags,
+				"labels": n.Domain.Labels,
+			},
+		},
+	})
+}
+
+// ResolveFailure resolves the alert for an endpoint whose check recovered
+func (p *PagerDutyNotifier) ResolveFailure(ctx context.Context, n Notification) error {
+	return p.post(ctx, pagerDutyEvent{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    pagerDutyFailureDedupKey(n),
+	})
+}
+
+// Name returns the name of the notifier
+func (p *PagerDutyNotifier) Name() string {
+	return "PagerDuty"
+}
+
+// httpClient returns the client used for requests
+func (p *PagerDutyNotifier) httpClient() *http.Client {
+	return p.client
+}
+
+// post sends an event to the Events API
+func (p *PagerDutyNotifier) post(ctx context.Context, event pagerDutyEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.URL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			Message string   `json:"message"`
+			Errors  []string `json:"errors"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Message != "" {
+			return fmt.Errorf("unexpected status code: %d: %s %v", resp.StatusCode, result.Message, result.Errors)
+		}
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// pagerDutyDedupKey returns a key that is stable per endpoint and threshold,
+// so repeated reminders update the same alert
+func pagerDutyDedupKey(n Notification) string {
+	return fmt.Sprintf("ssl-cert-monitor/%s/%d", n.Endpoint(), n.Threshold)
+}
+
+// pagerDutyFailureDedupKey returns the key of the failed check alert for
+// an endpoint
+func pagerDutyFailureDedupKey(n Notification) string {
+	return fmt.Sprintf("ssl-cert-monitor/%s/check", n.Endpoint())
+}
+
+// pagerDutySeverity maps a notification severity to a PagerDuty severity
+func pagerDutySeverity(s Severity) string {
+	switch s {
+	case SeverityExpired, SeverityCritical:
+		return "critical"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
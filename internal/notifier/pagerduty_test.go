@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestPagerDutyTriggerAndResolve(t *testing.T) {
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p, err := NewPagerDutyNotifier(config.PagerDutyConfig{RoutingKey: "key", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Group = "web"
+	if err := p.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Resolve(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "key" {
+		t.Errorf("trigger = %+v", trigger)
+	}
+	if trigger.DedupKey != "ssl-cert-monitor/a.example.com:443/7" {
+		t.Errorf("dedup key = %q", trigger.DedupKey)
+	}
+	if p := trigger.Payload; p == nil || p.Severity != "critical" || p.Source != "a.example.com:443" || p.Group != "web" {
+		t.Errorf("payload = %+v", trigger.Payload)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey || resolve.Payload != nil {
+		t.Errorf("resolve = %+v, want the trigger's dedup key and no payload", resolve)
+	}
+}
+
+func TestPagerDutyCheckFailure(t *testing.T) {
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p, err := NewPagerDutyNotifier(config.PagerDutyConfig{RoutingKey: "key", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := Notification{Domain: config.DomainConfig{Host: "a.example.com", Port: 443}}
+	if err := p.SendFailure(context.Background(), n, errors.New("connection refused")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ResolveFailure(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.DedupKey != "ssl-cert-monitor/a.example.com:443/check" {
+		t.Errorf("trigger = %+v", trigger)
+	}
+	if p := trigger.Payload; p == nil || p.Severity != "error" || p.CustomDetails["error"] != "connection refused" {
+		t.Errorf("payload = %+v", trigger.Payload)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("resolve = %+v, want the trigger's dedup key", resolve)
+	}
+}
+
+func TestPagerDutyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"status":"invalid event","message":"Event object is invalid","errors":["Length of 'routing_key' is incorrect"]}`)
+	}))
+	defer srv.Close()
+
+	p, err := NewPagerDutyNotifier(config.PagerDutyConfig{RoutingKey: "key", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "400: Event object is invalid") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := []struct {
+		days float64
+		want string
+	}{
+		{-1, "critical"},
+		{3, "critical"},
+		{20, "warning"},
+		{60, "info"},
+	}
+	for _, tt := range tests {
+		n := Notification{DaysRemaining: tt.days}
+		if got := pagerDutySeverity(n.Severity()); got != tt.want {
+			t.Errorf("severity for %v days = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("pushover", config.PushoverConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.PushoverConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewPushoverNotifier(cfg)
+	})
+}
+
+// PushoverNotifier sends notifications through the Pushover API
+type PushoverNotifier struct {
+	config    config.PushoverConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewPushoverNotifier creates a new Pushover notifier
+func NewPushoverNotifier(cfg config.PushoverConfig) (*PushoverNotifier, error) {
+	if cfg.APIToken == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+	if cfg.UserKey == "" {
+		return nil, fmt.Errorf("user key is required")
+	}
+	config.ApplyDefaults(&cfg)
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	// Pushover requires at least 30 seconds between emergency retries
+	if cfg.Retry < 30 {
+		return nil, fmt.Errorf("retry must be at least 30 seconds")
+	}
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushoverNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// Send sends a notification to Pushover
+func (p *PushoverNotifier) Send(ctx context.Context, n Notification) error {
+	domainName := n.Domain.DisplayName()
+
+	text, err := p.templates.render(n)
+	if err != nil {
+		return err
+	}
+	priority := pushoverPriority(n.Severity())
+	form := url.Values{
+		"token":     {p.config.APIToken},
+		"user":      {p.config.UserKey},
+		"title":     {text.titleOr(expirySummary(domainName, n.DaysRemaining))},
+		"message":   {text.bodyOr(plainTextMessage(n))},
+		"priority":  {strconv.Itoa(priority)},
+		"timestamp": {strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	if n.URL() != "" {
+		form.Set("url", n.URL())
+		form.Set("url_title", "Open "+n.Domain.Host)
+	}
+	if priority == 2 {
+		form.Set("retry", strconv.Itoa(p.config.Retry))
+		form.Set("expire", strconv.Itoa(p.config.Expire))
+	}
+	if p.config.Device != "" {
+		form.Set("device", p.config.Device)
+	}
+	if p.config.Sound != "" {
+		form.Set("sound", p.config.Sound)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.APIURL+"/1/messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			Errors []string `json:"errors"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&result) == nil && len(result.Errors) > 0 {
+			return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+		}
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (p *PushoverNotifier) Name() string {
+	return "Pushover"
+}
+
+// httpClient returns the client used for requests
+func (p *PushoverNotifier) httpClient() *http.Client {
+	return p.client
+}
+
+// pushoverPriority maps a severity to a Pushover priority (-2 to 2).
+// Expired certificates use emergency priority, which repeats until acknowledged.
+func pushoverPriority(s Severity) int {
+	switch s {
+	case SeverityExpired:
+		return 2
+	case SeverityCritical:
+		return 1
+	case SeverityWarning:
+		return 0
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestPushoverSend(t *testing.T) {
+	var forms []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/messages.json" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing form: %v", err)
+		}
+		forms = append(forms, r.PostForm)
+		io.WriteString(w, `{"status":1}`)
+	}))
+	defer srv.Close()
+
+	p, err := NewPushoverNotifier(config.PushoverConfig{APIToken: "app", UserKey: "user", APIURL: srv.URL + "/", Device: "phone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	if err := p.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	n.DaysRemaining = -1
+	n.Threshold = 0
+	if err := p.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(forms) != 2 {
+		t.Fatalf("got %d requests, want 2", len(forms))
+	}
+	critical, expired := forms[0], forms[1]
+	if critical.Get("token") != "app" || critical.Get("user") != "user" || critical.Get("device") != "phone" {
+		t.Errorf("form = %v, want the configured token, user key and device", critical)
+	}
+	if critical.Get("priority") != "1" || critical.Has("retry") {
+		t.Errorf("critical form = %v, want priority 1 without retry", critical)
+	}
+	// Emergency priority needs retry and expire
+	if expired.Get("priority") != "2" || expired.Get("retry") != "300" || expired.Get("expire") != "3600" {
+		t.Errorf("expired form = %v, want priority 2 with the default retry and expire", expired)
+	}
+}
+
+func TestPushoverErrors(t *testing.T) {
+	if _, err := NewPushoverNotifier(config.PushoverConfig{APIToken: "app", UserKey: "user", Retry: 10}); err == nil {
+		t.Error("expected an error for a retry below 30 seconds")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"user":"invalid","errors":["user identifier is not a valid user, group, or subscribed user key"],"status":0}`)
+	}))
+	defer srv.Close()
+
+	p, err := NewPushoverNotifier(config.PushoverConfig{APIToken: "app", UserKey: "user", APIURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "400: user identifier is not a valid") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestPushoverPriority(t *testing.T) {
+	tests := map[Severity]int{
+		SeverityExpired:  2,
+		SeverityCritical: 1,
+		SeverityWarning:  0,
+		SeverityInfo:     -1,
+	}
+	for severity, want := range tests {
+		if got := pushoverPriority(severity); got != want {
+			t.Errorf("pushoverPriority(%s) = %d, want %d", severity, got, want)
+		}
+	}
+}
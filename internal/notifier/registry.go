@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// Factory creates a notifier from a notifier definition
+type Factory func(def config.NotifierConfig, env Env) (Notifier, error)
+
+// Env holds shared dependencies made available to notifier factories
+type Env struct {
+	// Threads persists message thread IDs so follow-up notifications can be
+	// posted as replies. It may be nil.
+	Threads ThreadStore
+	// HTTPTimeout limits the requests of HTTP based notifiers. The default
+	// of 10 seconds is kept when it is zero.
+	HTTPTimeout time.Duration
+	// Proxy selects the proxy of HTTP based notifiers. The HTTPS_PROXY and
+	// NO_PROXY environment variables are used when it is nil.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// httpNotifier is implemented by notifiers sending HTTP requests, so shared
+// client settings can be applied to them
+type httpNotifier interface {
+	httpClient() *http.Client
+}
+
+// configureClient applies the shared HTTP settings to a notifier's client
+func (env Env) configureClient(n Notifier) {
+	h, ok := n.(httpNotifier)
+	if !ok {
+		return
+	}
+	client := h.httpClient()
+	if env.HTTPTimeout > 0 {
+		client.Timeout = env.HTTPTimeout
+	}
+	if env.Proxy != nil {
+		transport, ok := client.Transport.(*http.Transport)
+		if !ok {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+			client.Transport = transport
+		}
+		transport.Proxy = env.Proxy
+	}
+}
+
+// ThreadStore persists message thread identifiers between runs
+type ThreadStore interface {
+	GetThread(key string) (string, bool)
+	SetThread(key, id string) error
+	DeleteThread(key string) error
+}
+
+// registry maps notifier types to their factories
+var registry = make(map[string]Factory)
+
+// settingsTypes maps notifier types to their config structs
+var settingsTypes = make(map[string]reflect.Type)
+
+// Register makes a notifier type available to BuildNotifiers. Settings is
+// the zero value of the type's config struct, which describes its settings
+// (e.g. for the JSON schema). It is meant to be called from init functions.
+func Register(typ string, settings interface{}, factory Factory) {
+	if _, exists := registry[typ]; exists {
+		panic("notifier: type registered twice: " + typ)
+	}
+	registry[typ] = factory
+	settingsTypes[typ] = reflect.TypeOf(settings)
+}
+
+// SettingsType returns the config struct of a registered notifier type, or
+// nil if the type is unknown
+func SettingsType(typ string) reflect.Type {
+	return settingsTypes[typ]
+}
+
+// Types returns the registered notifier types in sorted order
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for typ := range registry {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,31 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// TestBuildExampleNotifiers builds every notifier of the example config, so
+// it stays loadable and documents each registered type
+func TestBuildExampleNotifiers(t *testing.T) {
+	cfg, err := config.LoadConfig("../../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := make(map[string]bool)
+	for i, n := range cfg.Notifications {
+		cfg.Notifications[i].Enabled = true
+		documented[n.Type] = true
+	}
+	for _, typ := range Types() {
+		if !documented[typ] {
+			t.Errorf("notifier type %s is missing from the example config", typ)
+		}
+	}
+
+	if _, err := BuildNotifiers(cfg, Env{}); err != nil {
+		t.Fatal(err)
+	}
+}
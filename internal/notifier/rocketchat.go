@@ -0,0 +1,138 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("rocketchat", config.RocketChatConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.RocketChatConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewRocketChatNotifier(cfg)
+	})
+}
+
+// RocketChatNotifier sends notifications to Rocket.Chat via incoming webhook
+type RocketChatNotifier struct {
+	config    config.RocketChatConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewRocketChatNotifier creates a new Rocket.Chat notifier
+func NewRocketChatNotifier(cfg config.RocketChatConfig) (*RocketChatNotifier, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RocketChatNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// rocketChatMessage represents the payload sent to Rocket.Chat
+type rocketChatMessage struct {
+	Text        string                 `json:"text"`
+	Channel     string                 `json:"channel,omitempty"`
+	Alias       string                 `json:"alias,omitempty"`
+	Emoji       string                 `json:"emoji,omitempty"`
+	Avatar      string                 `json:"avatar,omitempty"`
+	Attachments []rocketChatAttachment `json:"attachments,omitempty"`
+}
+
+// rocketChatAttachment represents a message attachment
+type rocketChatAttachment struct {
+	Title  string            `json:"title,omitempty"`
+	Text   string            `json:"text,omitempty"`
+	Color  string            `json:"color,omitempty"`
+	Fields []rocketChatField `json:"fields,omitempty"`
+}
+
+// rocketChatField represents a field within an attachment
+type rocketChatField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Send sends a notification to Rocket.Chat
+func (r *RocketChatNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := r.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := r.buildMessage(n, text)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Rocket.Chat message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", r.config.WebhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (r *RocketChatNotifier) Name() string {
+	return "Rocket.Chat"
+}
+
+// httpClient returns the client used for requests
+func (r *RocketChatNotifier) httpClient() *http.Client {
+	return r.client
+}
+
+// buildMessage constructs the Rocket.Chat message
+func (r *RocketChatNotifier) buildMessage(n Notification, text messageText) rocketChatMessage {
+	domainName := n.Domain.DisplayName()
+
+	var fields []rocketChatField
+	for _, field := range messageFields(n) {
+		fields = append(fields, rocketChatField{Title: field.Label, Value: field.Value, Short: true})
+	}
+
+	return rocketChatMessage{
+		Text:    "⚠️ SSL Certificate Expiry Alert",
+		Channel: r.config.Channel,
+		Alias:   r.config.Alias,
+		Emoji:   r.config.Emoji,
+		Avatar:  r.config.Avatar,
+		Attachments: []rocketChatAttachment{
+			{
+				Title:  text.titleOr(expiryHeadline(domainName, n)),
+				Text:   text.bodyOr(n.Severity().Action()),
+				Color:  n.Severity().HexColor(),
+				Fields: fields,
+			},
+		},
+	}
+}
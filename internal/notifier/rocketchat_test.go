@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestRocketChatSend(t *testing.T) {
+	var message rocketChatMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := NewRocketChatNotifier(config.RocketChatConfig{WebhookURL: srv.URL, Channel: "#alerts", Alias: "monitor", Emoji: ":lock:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.DaysRemaining = -1
+	n.Threshold = 0
+	if err := r.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Channel != "#alerts" || message.Alias != "monitor" || message.Emoji != ":lock:" {
+		t.Errorf("message = %+v, want the configured channel, alias and emoji", message)
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(message.Attachments))
+	}
+	attachment := message.Attachments[0]
+	if attachment.Title != "Certificate for a.example.com has expired!" {
+		t.Errorf("title = %q", attachment.Title)
+	}
+	if attachment.Text != SeverityExpired.Action() || attachment.Color != SeverityExpired.HexColor() {
+		t.Errorf("text and color = %q %q, want those of an expired certificate", attachment.Text, attachment.Color)
+	}
+}
+
+func TestRocketChatTemplates(t *testing.T) {
+	var message rocketChatMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := NewRocketChatNotifier(config.RocketChatConfig{
+		WebhookURL: srv.URL,
+		Templates:  config.TemplateConfig{Title: "{{.Domain}} expiring", Body: "{{.Threshold}} day reminder"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	if got := message.Attachments[0]; got.Title != "a.example.com expiring" || got.Text != "7 day reminder" {
+		t.Errorf("title and text = %q %q", got.Title, got.Text)
+	}
+}
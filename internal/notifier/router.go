@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// Router decides which notifiers receive a notification based on the
+// configured routing rules
+type Router struct {
+	routes []config.RouteConfig
+}
+
+// NewRouter creates a router, validating the rule conditions
+func NewRouter(routes []config.RouteConfig) (*Router, error) {
+	var errs []error
+	for i, route := range routes {
+		name := route.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, s := range route.Match.Severity {
+			if !validSeverity(s) {
+				errs = append(errs, withSource(route.Source, fmt.Errorf("route %s: unknown severity %q", name, s)))
+			}
+		}
+		for _, pattern := range route.Match.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, withSource(route.Source, fmt.Errorf("route %s: invalid host pattern %q: %w", name, pattern, err)))
+			}
+		}
+		if route.Match.MinDays != nil && route.Match.MaxDays != nil && *route.Match.MinDays > *route.Match.MaxDays {
+			errs = append(errs, withSource(route.Source, fmt.Errorf("route %s: min_days is greater than max_days", name)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Router{routes: routes}, nil
+}
+
+// Notifiers returns every notifier name referenced by the routing rules
+func (r *Router) Notifiers() []string {
+	var names []string
+	for _, route := range r.routes {
+		names = append(names, route.Notifiers...)
+	}
+	return names
+}
+
+// Route returns the notifier names for a notification. The second result is
+// false when no rule matched, in which case the caller should fall back to
+// its default targets.
+func (r *Router) Route(n Notification) ([]string, bool) {
+	return r.route(n, false)
+}
+
+// RouteFailure is like Route for a domain that could not be checked. Rules
+// with severity or days conditions never match, since neither is known.
+func (r *Router) RouteFailure(domain config.DomainConfig) ([]string, bool) {
+	return r.route(Notification{Domain: domain}, true)
+}
+
+// route returns the notifier names of the matching rules, skipping rules
+// with expiry conditions if failure is set
+func (r *Router) route(n Notification, failure bool) ([]string, bool) {
+	var names []string
+	matched := false
+
+	for _, route := range r.routes {
+		if failure && hasExpiryConditions(route.Match) {
+			continue
+		}
+		if !matches(route.Match, n) {
+			continue
+		}
+		matched = true
+		for _, name := range route.Notifiers {
+			if !containsName(names, name) {
+				names = append(names, name)
+			}
+		}
+		if !route.Continue {
+			break
+		}
+	}
+
+	return names, matched
+}
+
+// hasExpiryConditions reports whether a rule depends on the certificate's
+// expiry
+func hasExpiryConditions(m config.RouteMatch) bool {
+	return len(m.Severity) > 0 || m.MinDays != nil || m.MaxDays != nil
+}
+
+// matches reports whether a notification satisfies all conditions of a rule
+func matches(m config.RouteMatch, n Notification) bool {
+	domain := n.Domain
+
+	if len(m.Groups) > 0 && !containsName(m.Groups, domain.Group) {
+		return false
+	}
+	for _, tag := range m.Tags {
+		if !containsName(domain.Tags, tag) {
+			return false
+		}
+	}
+	for key, value := range m.Labels {
+		if domain.Labels[key] != value {
+			return false
+		}
+	}
+	if len(m.Hosts) > 0 {
+		// Keystore entries match by path#alias as well as by the path alone
+		names := []string{strings.ToLower(domain.Key())}
+		if domain.Alias != "" {
+			names = append(names, strings.ToLower(domain.File))
+		}
+		found := false
+		for _, pattern := range m.Hosts {
+			for _, name := range names {
+				if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Severity) > 0 && !containsName(m.Severity, string(n.Severity())) {
+		return false
+	}
+	if m.MinDays != nil && n.DaysRemaining < *m.MinDays {
+		return false
+	}
+	if m.MaxDays != nil && n.DaysRemaining > *m.MaxDays {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// days returns a pointer for the min_days and max_days conditions
+func days(d float64) *float64 {
+	return &d
+}
+
+func TestRoute(t *testing.T) {
+	domain := config.DomainConfig{
+		Host:   "api.eu.example.com",
+		Port:   443,
+		Group:  "Platform",
+		Tags:   []string{"Customer-Facing", "eu"},
+		Labels: map[string]string{"team": "payments"},
+	}
+	tests := []struct {
+		name    string
+		routes  []config.RouteConfig
+		days    float64
+		want    []string
+		matched bool
+	}{
+		{"no rules", nil, 3, nil, false},
+		{"tags ignore case", []config.RouteConfig{{Match: config.RouteMatch{Tags: []string{"customer-facing"}}, Notifiers: []string{"pager"}}}, 3, []string{"pager"}, true},
+		{"all tags required", []config.RouteConfig{{Match: config.RouteMatch{Tags: []string{"eu", "internal"}}, Notifiers: []string{"pager"}}}, 3, nil, false},
+		{"group", []config.RouteConfig{{Match: config.RouteMatch{Groups: []string{"web", "platform"}}, Notifiers: []string{"slack"}}}, 3, []string{"slack"}, true},
+		{"other group", []config.RouteConfig{{Match: config.RouteMatch{Groups: []string{"web"}}, Notifiers: []string{"slack"}}}, 3, nil, false},
+		{"label", []config.RouteConfig{{Match: config.RouteMatch{Labels: map[string]string{"team": "payments"}}, Notifiers: []string{"slack"}}}, 3, []string{"slack"}, true},
+		{"label value is exact", []config.RouteConfig{{Match: config.RouteMatch{Labels: map[string]string{"team": "Payments"}}, Notifiers: []string{"slack"}}}, 3, nil, false},
+		{"host glob", []config.RouteConfig{{Match: config.RouteMatch{Hosts: []string{"*.EU.example.com"}}, Notifiers: []string{"email"}}}, 3, []string{"email"}, true},
+		{"host glob crosses dots", []config.RouteConfig{{Match: config.RouteMatch{Hosts: []string{"*.example.com"}}, Notifiers: []string{"email"}}}, 3, []string{"email"}, true},
+		{"other host", []config.RouteConfig{{Match: config.RouteMatch{Hosts: []string{"*.example.org", "example.com"}}, Notifiers: []string{"email"}}}, 3, nil, false},
+		{"severity", []config.RouteConfig{{Match: config.RouteMatch{Severity: []string{"critical", "expired"}}, Notifiers: []string{"pager"}}}, 3, []string{"pager"}, true},
+		{"expired severity", []config.RouteConfig{{Match: config.RouteMatch{Severity: []string{"expired"}}, Notifiers: []string{"pager"}}}, -1, []string{"pager"}, true},
+		{"other severity", []config.RouteConfig{{Match: config.RouteMatch{Severity: []string{"warning"}}, Notifiers: []string{"pager"}}}, 3, nil, false},
+		{"within min and max days", []config.RouteConfig{{Match: config.RouteMatch{MinDays: days(3), MaxDays: days(7)}, Notifiers: []string{"email"}}}, 3, []string{"email"}, true},
+		{"below min days", []config.RouteConfig{{Match: config.RouteMatch{MinDays: days(3)}, Notifiers: []string{"email"}}}, 2.9, nil, false},
+		{"above max days", []config.RouteConfig{{Match: config.RouteMatch{MaxDays: days(7)}, Notifiers: []string{"email"}}}, 7.5, nil, false},
+		{
+			"first match wins",
+			[]config.RouteConfig{
+				{Match: config.RouteMatch{Tags: []string{"eu"}}, Notifiers: []string{"slack"}},
+				{Match: config.RouteMatch{}, Notifiers: []string{"email"}},
+			},
+			3, []string{"slack"}, true,
+		},
+		{
+			"continue",
+			[]config.RouteConfig{
+				{Match: config.RouteMatch{Tags: []string{"eu"}}, Notifiers: []string{"pager", "slack"}, Continue: true},
+				{Match: config.RouteMatch{Groups: []string{"web"}}, Notifiers: []string{"teams"}},
+				{Match: config.RouteMatch{}, Notifiers: []string{"SLACK", "email"}},
+				{Match: config.RouteMatch{}, Notifiers: []string{"discord"}},
+			},
+			3, []string{"pager", "slack", "email"}, true,
+		},
+		{
+			"default route",
+			[]config.RouteConfig{
+				{Match: config.RouteMatch{Tags: []string{"internal"}}, Notifiers: []string{"slack"}},
+				{Match: config.RouteMatch{}, Notifiers: []string{"email"}},
+			},
+			3, []string{"email"}, true,
+		},
+		{"drop", []config.RouteConfig{{Match: config.RouteMatch{Severity: []string{"critical"}}}}, 3, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRouter(tt.routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, matched := r.Route(Notification{Domain: domain, DaysRemaining: tt.days})
+			if !reflect.DeepEqual(got, tt.want) || matched != tt.matched {
+				t.Errorf("Route = %v, %v, want %v, %v", got, matched, tt.want, tt.matched)
+			}
+		})
+	}
+}
+
+func TestRouteFailure(t *testing.T) {
+	r, err := NewRouter([]config.RouteConfig{
+		{Match: config.RouteMatch{Groups: []string{"web"}, MaxDays: days(3)}, Notifiers: []string{"pager"}},
+		{Match: config.RouteMatch{Groups: []string{"web"}, Severity: []string{"critical"}}, Notifiers: []string{"pager"}},
+		{Match: config.RouteMatch{Groups: []string{"web"}}, Notifiers: []string{"opsgenie"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, matched := r.RouteFailure(config.DomainConfig{Host: "a.example.com", Port: 443, Group: "web"})
+	if !matched || !reflect.DeepEqual(names, []string{"opsgenie"}) {
+		t.Errorf("RouteFailure = %v, %v; want the rule without expiry conditions", names, matched)
+	}
+	if names, matched := r.RouteFailure(config.DomainConfig{Host: "a.example.com", Port: 443}); matched {
+		t.Errorf("RouteFailure = %v, want no match", names)
+	}
+}
+
+func TestRouteKeystoreEntries(t *testing.T) {
+	r, err := NewRouter([]config.RouteConfig{{Match: config.RouteMatch{Hosts: []string{"/etc/ssl/*.jks"}}, Notifiers: []string{"email"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []config.DomainConfig{
+		{File: "/etc/ssl/app.jks", Alias: "server"},
+		{File: "/etc/ssl/app.jks"},
+	} {
+		if got, _ := r.Route(Notification{Domain: domain}); !reflect.DeepEqual(got, []string{"email"}) {
+			t.Errorf("Route(%s) = %v", domain.Key(), got)
+		}
+	}
+	if got, matched := r.Route(Notification{Domain: config.DomainConfig{File: "/etc/ssl/old/app.jks"}}); matched {
+		t.Errorf("* matched a / in a file path: %v", got)
+	}
+}
+
+func TestNewRouterErrors(t *testing.T) {
+	_, err := NewRouter([]config.RouteConfig{
+		{Name: "pages", Match: config.RouteMatch{Severity: []string{"urgent"}}},
+		{Match: config.RouteMatch{Hosts: []string{"[a-"}}},
+		{Match: config.RouteMatch{MinDays: days(7), MaxDays: days(3)}, Source: "config.yaml:12"},
+	})
+	if err == nil {
+		t.Fatal("invalid rules were accepted")
+	}
+	for _, want := range []string{
+		`route pages: unknown severity "urgent"`,
+		`route #2: invalid host pattern "[a-"`,
+		"config.yaml:12: route #3: min_days is greater than max_days",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import "fmt"
+
+// Severity classifies how urgent a notification is
+type Severity string
+
+// Severity levels, from least to most urgent
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+	SeverityExpired  Severity = "expired"
+)
+
+// SeverityFor returns the severity for the given days remaining
+func SeverityFor(daysRemaining float64) Severity {
+	switch {
+	case daysRemaining <= 0:
+		return SeverityExpired
+	case daysRemaining <= 7:
+		return SeverityCritical
+	case daysRemaining <= 30:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// validSeverity reports whether s names a known severity
+func validSeverity(s string) bool {
+	switch Severity(s) {
+	case SeverityInfo, SeverityWarning, SeverityCritical, SeverityExpired:
+		return true
+	}
+	return false
+}
+
+// Color returns the color used for the severity in chat messages, as 0xRRGGBB
+func (s Severity) Color() int {
+	switch s {
+	case SeverityExpired, SeverityCritical:
+		return 0xFF0000 // Red
+	case SeverityWarning:
+		return 0xFFA500 // Orange
+	default:
+		return 0x00FF00 // Green
+	}
+}
+
+// HexColor returns the severity color in #RRGGBB notation
+func (s Severity) HexColor() string {
+	return fmt.Sprintf("#%06X", s.Color())
+}
+
+// Action returns the recommended action for the severity
+func (s Severity) Action() string {
+	switch s {
+	case SeverityExpired:
+		return "Certificate has expired! Please renew immediately."
+	case SeverityCritical:
+		return "Certificate expires soon! Please renew immediately."
+	case SeverityWarning:
+		return "Certificate expires within 30 days. Plan for renewal."
+	default:
+		return "Certificate expiry approaching. Monitor regularly."
+	}
+}
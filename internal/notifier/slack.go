@@ -6,39 +6,101 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/config"
 )
 
-// SlackNotifier sends notifications to Slack via webhook
+// SlackNotifier sends notifications to Slack via webhook, or via
+// chat.postMessage when a bot token is configured
 type SlackNotifier struct {
-	config config.SlackConfig
-	client *http.Client
+	config    config.SlackConfig
+	client    *http.Client
+	threads   ThreadStore
+	templates *messageTemplates
 }
 
-// NewSlackNotifier creates a new Slack notifier
-func NewSlackNotifier(cfg config.SlackConfig) (*SlackNotifier, error) {
-	if cfg.WebhookURL == "" {
-		return nil, fmt.Errorf("webhook URL is required")
+func init() {
+	Register("slack", config.SlackConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.SlackConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewSlackNotifier(cfg, env.Threads)
+	})
+}
+
+// NewSlackNotifier creates a new Slack notifier. In bot mode, threads (which
+// may be nil) remembers the first message per endpoint so reminders are
+// posted as replies to it.
+func NewSlackNotifier(cfg config.SlackConfig, threads ThreadStore) (*SlackNotifier, error) {
+	config.ApplyDefaults(&cfg)
+	if cfg.BotToken != "" {
+		if cfg.Channel == "" {
+			return nil, fmt.Errorf("channel is required with a bot token")
+		}
+		cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	} else if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook URL or bot token is required")
 	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SlackNotifier{
-		config: cfg,
-		client: &http.Client{Timeout: 10 * time.Second},
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		threads:   threads,
+		templates: templates,
 	}, nil
 }
 
 // slackMessage represents the payload sent to Slack
 type slackMessage struct {
-	Text      string `json:"text,omitempty"`
-	Username  string `json:"username,omitempty"`
-	IconEmoji string `json:"icon_emoji,omitempty"`
-	Channel   string `json:"channel,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Blocks    []slackBlock `json:"blocks,omitempty"`
+	Username  string       `json:"username,omitempty"`
+	IconEmoji string       `json:"icon_emoji,omitempty"`
+	Channel   string       `json:"channel,omitempty"`
+	ThreadTS  string       `json:"thread_ts,omitempty"`
+}
+
+// slackBlock represents a Block Kit layout block
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Fields   []slackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// slackText represents a Block Kit text object
+type slackText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// slackButton represents a Block Kit link button
+type slackButton struct {
+	Type     string    `json:"type"`
+	Text     slackText `json:"text"`
+	URL      string    `json:"url"`
+	ActionID string    `json:"action_id"`
 }
 
 // Send sends a notification to Slack
 func (s *SlackNotifier) Send(ctx context.Context, n Notification) error {
-	message := s.buildMessage(n)
+	text, err := s.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := s.buildMessage(n, text)
+	if s.config.BotToken != "" {
+		return s.sendThreaded(ctx, n, message)
+	}
+
 	payload, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Slack message: %w", err)
@@ -63,21 +125,118 @@ func (s *SlackNotifier) Send(ctx context.Context, n Notification) error {
 	return nil
 }
 
+// Resolve posts a renewal note into the endpoint's thread and forgets the
+// thread, so alerts for the new certificate start a fresh one. It is a no-op
+// in webhook mode.
+func (s *SlackNotifier) Resolve(ctx context.Context, n Notification) error {
+	if s.config.BotToken == "" || s.threads == nil {
+		return nil
+	}
+	key := s.threadKey(n)
+	ts, ok := s.threads.GetThread(key)
+	if !ok {
+		return nil
+	}
+
+	message := slackMessage{
+		Text: fmt.Sprintf("✅ Certificate for %s has been renewed and now expires on %s",
+			slackEscape(n.Endpoint()), n.Expiry.Format("2006-01-02")),
+		Username:  s.config.Username,
+		IconEmoji: s.config.IconEmoji,
+		Channel:   s.config.Channel,
+		ThreadTS:  ts,
+	}
+	if _, err := s.postMessage(ctx, message); err != nil {
+		return err
+	}
+	return s.threads.DeleteThread(key)
+}
+
 // Name returns the name of the notifier
 func (s *SlackNotifier) Name() string {
 	return "Slack"
 }
 
-// buildMessage constructs the Slack message
-func (s *SlackNotifier) buildMessage(n Notification) slackMessage {
-	domainName := n.Domain.Name
-	if domainName == "" {
-		domainName = n.Domain.Host
+// httpClient returns the client used for requests
+func (s *SlackNotifier) httpClient() *http.Client {
+	return s.client
+}
+
+// sendThreaded posts the message with chat.postMessage as a reply to the
+// endpoint's first alert, starting the thread if there is none yet
+func (s *SlackNotifier) sendThreaded(ctx context.Context, n Notification, message slackMessage) error {
+	key := s.threadKey(n)
+	if s.threads != nil {
+		if ts, ok := s.threads.GetThread(key); ok {
+			message.ThreadTS = ts
+		}
+	}
+
+	ts, err := s.postMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	if s.threads != nil && message.ThreadTS == "" {
+		if err := s.threads.SetThread(key, ts); err != nil {
+			return fmt.Errorf("failed to store thread: %w", err)
+		}
+	}
+	return nil
+}
+
+// postMessage calls chat.postMessage and returns the timestamp of the
+// posted message
+func (s *SlackNotifier) postMessage(ctx context.Context, message slackMessage) (string, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Slack message: %w", err)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, "POST", s.config.APIURL+"/chat.postMessage", bytes.NewBuffer(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.config.BotToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// The Web API reports errors in the body with a 200 status
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !result.OK {
+		return "", fmt.Errorf("chat.postMessage failed: %s", result.Error)
+	}
+
+	return result.TS, nil
+}
+
+// threadKey identifies the thread for an endpoint in a channel
+func (s *SlackNotifier) threadKey(n Notification) string {
+	return fmt.Sprintf("slack/%s/%s", s.config.Channel, n.Endpoint())
+}
+
+// buildMessage constructs the Slack message
+func (s *SlackNotifier) buildMessage(n Notification, text messageText) slackMessage {
+	domainName := n.Domain.DisplayName()
+
 	message := fmt.Sprintf(
-		"⚠️ SSL Certificate Expiry Alert\n"+
-			"*Domain:* %s\n"+
+		"*Domain:* %s\n"+
 			"*Days Remaining:* %.1f\n"+
 			"*Expiry Date:* %s\n"+
 			"*Threshold:* %d days\n"+
@@ -88,11 +247,99 @@ func (s *SlackNotifier) buildMessage(n Notification) slackMessage {
 		n.Threshold,
 		time.Now().Format("2006-01-02 15:04:05 MST"),
 	)
+	if n.Domain.Group != "" {
+		message += fmt.Sprintf("\n*Group:* %s", n.Domain.Group)
+	}
+	if len(n.Domain.Tags) > 0 {
+		message += fmt.Sprintf("\n*Tags:* %s", strings.Join(n.Domain.Tags, ", "))
+	}
+	if len(n.Domain.Labels) > 0 {
+		message += fmt.Sprintf("\n*Labels:* %s", formatLabels(n.Domain.Labels))
+	}
+	title := text.titleOr("⚠️ SSL Certificate Expiry Alert")
+	message = title + "\n" + text.bodyOr(message)
 
-	return slackMessage{
+	result := slackMessage{
 		Text:      message,
 		Username:  s.config.Username,
 		IconEmoji: s.config.IconEmoji,
 		Channel:   s.config.Channel,
 	}
-}
\ No newline at end of file
+	if s.config.BlockKit {
+		// The text becomes the fallback for notifications
+		result.Text = text.titleOr("⚠️ " + expirySummary(domainName, n.DaysRemaining))
+		result.Blocks = buildSlackBlocks(n, text)
+	}
+	return result
+}
+
+// buildSlackBlocks constructs the Block Kit layout for a notification.
+// Rendered templates replace the header and the summary section.
+func buildSlackBlocks(n Notification, text messageText) []slackBlock {
+	domainName := n.Domain.DisplayName()
+
+	summary := fmt.Sprintf("Certificate for *%s* expires in *%.1f days*.", slackEscape(domainName), n.DaysRemaining)
+	if n.Severity() == SeverityExpired {
+		summary = fmt.Sprintf("Certificate for *%s* has *expired*.", slackEscape(domainName))
+	}
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: text.titleOr("⚠️ SSL Certificate Expiry Alert"), Emoji: true},
+		},
+		{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: text.bodyOr(summary + "\n" + n.Severity().Action()),
+			},
+		},
+	}
+
+	// Sections hold at most 10 fields
+	var fields []slackText
+	for _, field := range messageFields(n) {
+		fields = append(fields, slackText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*%s:*\n%s", field.Label, slackEscape(field.Value)),
+		})
+	}
+	for len(fields) > 0 {
+		count := len(fields)
+		if count > 10 {
+			count = 10
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields[:count]})
+		fields = fields[count:]
+	}
+
+	blocks = append(blocks, slackBlock{
+		Type: "context",
+		Elements: []interface{}{
+			slackText{Type: "mrkdwn", Text: fmt.Sprintf("Severity: *%s* | SSL Certificate Monitor", n.Severity())},
+		},
+	})
+	if n.URL() != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "actions",
+			Elements: []interface{}{
+				slackButton{
+					Type:     "button",
+					Text:     slackText{Type: "plain_text", Text: "Open " + n.Domain.Host},
+					URL:      n.URL(),
+					ActionID: "open_host",
+				},
+			},
+		})
+	}
+
+	return blocks
+}
+
+// slackEscaper escapes the control characters of Slack mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape escapes text for use in Slack mrkdwn
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
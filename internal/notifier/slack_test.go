@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// memoryThreads is a ThreadStore kept in memory
+type memoryThreads map[string]string
+
+func (m memoryThreads) GetThread(key string) (string, bool) {
+	id, ok := m[key]
+	return id, ok
+}
+
+func (m memoryThreads) SetThread(key, id string) error {
+	m[key] = id
+	return nil
+}
+
+func (m memoryThreads) DeleteThread(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// slackAPI is a chat.postMessage endpoint that records the messages it
+// receives and answers with increasing timestamps
+type slackAPI struct {
+	*httptest.Server
+	mu       sync.Mutex
+	messages []slackMessage
+}
+
+func newSlackAPI(t *testing.T) *slackAPI {
+	api := &slackAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb-token" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+			return
+		}
+		var message slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		api.mu.Lock()
+		api.messages = append(api.messages, message)
+		fmt.Fprintf(w, `{"ok":true,"ts":"100.%d"}`, len(api.messages))
+		api.mu.Unlock()
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func TestSlackThreads(t *testing.T) {
+	api := newSlackAPI(t)
+	threads := memoryThreads{}
+	s, err := NewSlackNotifier(config.SlackConfig{BotToken: "xoxb-token", Channel: "#certs", APIURL: api.URL + "/"}, threads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+
+	for i := 0; i < 2; i++ {
+		if err := s.Send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Resolve(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.messages) != 3 {
+		t.Fatalf("got %d messages, want 3", len(api.messages))
+	}
+	if first := api.messages[0]; first.ThreadTS != "" || first.Channel != "#certs" {
+		t.Errorf("first message = %+v, want a new thread in #certs", first)
+	}
+	if ts := api.messages[1].ThreadTS; ts != "100.1" {
+		t.Errorf("reminder thread_ts = %q, want 100.1", ts)
+	}
+	if resolved := api.messages[2]; resolved.ThreadTS != "100.1" || !strings.Contains(resolved.Text, "renewed") {
+		t.Errorf("resolve message = %+v", resolved)
+	}
+	if len(threads) != 0 {
+		t.Errorf("threads after resolve = %v, want none", threads)
+	}
+
+	// Resolving without a thread posts nothing
+	if err := s.Resolve(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.messages) != 3 {
+		t.Errorf("got %d messages, want 3", len(api.messages))
+	}
+}
+
+func TestSlackAPIError(t *testing.T) {
+	api := newSlackAPI(t)
+	s, err := NewSlackNotifier(config.SlackConfig{BotToken: "wrong", Channel: "#certs", APIURL: api.URL}, memoryThreads{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("error = %v, want invalid_auth", err)
+	}
+}
+
+func TestSlackBlockKit(t *testing.T) {
+	var got slackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewSlackNotifier(config.SlackConfig{WebhookURL: srv.URL, BlockKit: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := Notification{
+		Domain:        config.DomainConfig{Host: "a<b>.example.com", Port: 8443},
+		DaysRemaining: 3,
+		Expiry:        time.Now(),
+		Threshold:     7,
+	}
+	if err := s.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(got.Text, "⚠️ ") {
+		t.Errorf("fallback text = %q", got.Text)
+	}
+	types := make([]string, len(got.Blocks))
+	for i, block := range got.Blocks {
+		types[i] = block.Type
+	}
+	if want := "header section section context actions"; strings.Join(types, " ") != want {
+		t.Errorf("blocks = %v, want %s", types, want)
+	}
+	if text := got.Blocks[1].Text.Text; !strings.Contains(text, "a&lt;b&gt;.example.com") {
+		t.Errorf("summary not escaped: %q", text)
+	}
+}
+
+func TestNewSlackNotifierErrors(t *testing.T) {
+	if _, err := NewSlackNotifier(config.SlackConfig{}, nil); err == nil {
+		t.Error("missing webhook URL and bot token was accepted")
+	}
+	if _, err := NewSlackNotifier(config.SlackConfig{BotToken: "xoxb-token"}, nil); err == nil {
+		t.Error("bot token without a channel was accepted")
+	}
+}
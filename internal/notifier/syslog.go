@@ -0,0 +1,239 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// syslogSDID is the structured data ID of the alert fields. 32473 is the
+// private enterprise number reserved for documentation (RFC 5612).
+const syslogSDID = "sslcert@32473"
+
+// syslogFacilities maps facility names to their codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func init() {
+	Register("syslog", config.SyslogConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.SyslogConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewSyslogNotifier(cfg)
+	})
+}
+
+// SyslogNotifier sends RFC 5424 messages to a syslog server
+type SyslogNotifier struct {
+	config    config.SyslogConfig
+	facility  int
+	tls       *tls.Config
+	templates *messageTemplates
+}
+
+// NewSyslogNotifier creates a new syslog notifier
+func NewSyslogNotifier(cfg config.SyslogConfig) (*SyslogNotifier, error) {
+	config.ApplyDefaults(&cfg)
+	switch cfg.Network {
+	case "unix":
+		if cfg.Address == "" {
+			cfg.Address = "/dev/log"
+		}
+	case "udp", "tcp", "tls":
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("address is required")
+		}
+	default:
+		return nil, fmt.Errorf("unknown network %q (use udp, tcp, tls or unix)", cfg.Network)
+	}
+
+	facility, ok := syslogFacilities[cfg.Facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown facility %q", cfg.Facility)
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.Network == "tls" {
+		var err error
+		tlsConfig, err = clientTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+	}
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyslogNotifier{
+		config:    cfg,
+		facility:  facility,
+		tls:       tlsConfig,
+		templates: templates,
+	}, nil
+}
+
+// Send writes the notification to the syslog server
+func (s *SyslogNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := s.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := s.buildMessage(n, text, time.Now())
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	// Stream transports use octet-counting framing (RFC 5425, RFC 6587)
+	if s.config.Network == "tcp" || s.config.Network == "tls" {
+		message = fmt.Sprintf("%d %s", len(message), message)
+	}
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return fmt.Errorf("failed to write syslog message: %w", err)
+	}
+	return nil
+}
+
+// Name returns the name of the notifier
+func (s *SyslogNotifier) Name() string {
+	return "Syslog"
+}
+
+// dial connects to the syslog server. Local sockets are tried as datagram
+// sockets first, which is what syslog daemons usually listen on.
+func (s *SyslogNotifier) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	switch s.config.Network {
+	case "tls":
+		return (&tls.Dialer{NetDialer: dialer, Config: s.tls}).DialContext(ctx, "tcp", s.config.Address)
+	case "unix":
+		conn, err := dialer.DialContext(ctx, "unixgram", s.config.Address)
+		if err == nil {
+			return conn, nil
+		}
+		return dialer.DialContext(ctx, "unix", s.config.Address)
+	default:
+		return dialer.DialContext(ctx, s.config.Network, s.config.Address)
+	}
+}
+
+// buildMessage formats an RFC 5424 message with the alert fields as
+// structured data
+func (s *SyslogNotifier) buildMessage(n Notification, text messageText, now time.Time) string {
+	domainName := n.Domain.DisplayName()
+	msg := text.bodyOr(text.titleOr(expirySummary(domainName, n.DaysRemaining)))
+
+	params := []struct{ name, value string }{
+		{"endpoint", n.Endpoint()},
+		{"days_remaining", fmt.Sprintf("%.1f", n.DaysRemaining)},
+		{"threshold", fmt.Sprintf("%d", n.Threshold)},
+		{"severity", string(n.Severity())},
+		{"expiry", n.Expiry.UTC().Format(time.RFC3339)},
+	}
+	if n.Domain.Group != "" {
+		params = append(params, struct{ name, value string }{"group", n.Domain.Group})
+	}
+	if len(n.Domain.Tags) > 0 {
+		params = append(params, struct{ name, value string }{"tags", strings.Join(n.Domain.Tags, ",")})
+	}
+	for _, key := range sortedKeys(n.Domain.Labels) {
+		params = append(params, struct{ name, value string }{syslogParamName("labels." + key), n.Domain.Labels[key]})
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	for _, param := range params {
+		sd.WriteString(fmt.Sprintf(` %s="%s"`, param.name, syslogSDEscaper.Replace(param.value)))
+	}
+	sd.WriteString("]")
+
+	priority := s.facility*8 + syslogSeverity(n.Severity())
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s%s",
+		priority,
+		now.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(s.config.Hostname, 255),
+		syslogHeaderField(s.config.AppName, 48),
+		os.Getpid(),
+		"CERT_EXPIRY",
+		sd.String(),
+		"\ufeff", // the BOM marks the message as UTF-8
+		msg,
+	)
+}
+
+// syslogSeverity maps a notification severity to a syslog severity
+func syslogSeverity(severity Severity) int {
+	switch severity {
+	case SeverityExpired:
+		return 2 // critical
+	case SeverityCritical:
+		return 3 // error
+	case SeverityWarning:
+		return 4 // warning
+	default:
+		return 6 // informational
+	}
+}
+
+// syslogSDEscaper escapes structured data parameter values
+var syslogSDEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamName restricts a structured data parameter name to the
+// printable ASCII characters RFC 5424 allows, at most 32 of them
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogHeaderField returns a header field restricted to printable ASCII
+// without spaces, or the NILVALUE when it is empty
+func syslogHeaderField(s string, max int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(field) > max {
+		field = field[:max]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestSyslogSend(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s, err := NewSyslogNotifier(config.SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Facility: "local3", Hostname: "monitor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	k, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local3 (19) * 8 + err (3)
+	if msg := string(buf[:k]); !strings.HasPrefix(msg, "<155>1 ") || !strings.Contains(msg, " monitor ssl-cert-monitor ") {
+		t.Errorf("message = %q", msg)
+	}
+}
+
+func TestSyslogStructuredData(t *testing.T) {
+	s, err := NewSyslogNotifier(config.SyslogConfig{Network: "udp", Address: "127.0.0.1:514"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Group = `prod "eu"`
+	n.Domain.Tags = []string{"shop", "api"}
+	n.Domain.Labels = map[string]string{"team": "payments", "cost center": "a]b"}
+
+	msg := s.buildMessage(n, messageText{}, time.Now())
+	for _, want := range []string{
+		`endpoint="a.example.com:443"`,
+		`severity="critical"`,
+		`group="prod \"eu\""`,
+		`tags="shop,api"`,
+		`labels.cost_center="a\]b" labels.team="payments"]`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestSyslogParamName(t *testing.T) {
+	tests := map[string]string{
+		"labels.team":                         "labels.team",
+		`labels.a=b "c"]`:                     "labels.a_b__c__",
+		"labels.a-very-long-label-key-indeed": "labels.a-very-long-label-key-ind",
+	}
+	for name, want := range tests {
+		if got := syslogParamName(name); got != want {
+			t.Errorf("syslogParamName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("teams", config.TeamsConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.TeamsConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewTeamsNotifier(cfg)
+	})
+}
+
+// TeamsNotifier sends Adaptive Card notifications to Microsoft Teams via an
+// incoming webhook or a Workflows (Power Automate) URL
+type TeamsNotifier struct {
+	config    config.TeamsConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewTeamsNotifier creates a new Teams notifier
+func NewTeamsNotifier(cfg config.TeamsConfig) (*TeamsNotifier, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeamsNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// teamsMessage is the message envelope carrying an Adaptive Card
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// teamsAttachment wraps an Adaptive Card in a message
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	ContentURL  *string   `json:"contentUrl"`
+	Content     teamsCard `json:"content"`
+}
+
+// teamsCard represents an Adaptive Card
+type teamsCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []interface{} `json:"body"`
+	MSTeams *teamsWidth   `json:"msteams,omitempty"`
+}
+
+// teamsWidth makes the card use the full message width in Teams
+type teamsWidth struct {
+	Width string `json:"width"`
+}
+
+// teamsContainer groups card elements with an optional style
+type teamsContainer struct {
+	Type  string        `json:"type"`
+	Style string        `json:"style,omitempty"`
+	Bleed bool          `json:"bleed,omitempty"`
+	Items []interface{} `json:"items"`
+}
+
+// teamsTextBlock is an Adaptive Card text element
+type teamsTextBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Wrap     bool   `json:"wrap,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+}
+
+// teamsFactSet is a list of name/value pairs
+type teamsFactSet struct {
+	Type  string      `json:"type"`
+	Facts []teamsFact `json:"facts"`
+}
+
+// teamsFact is a single name/value pair in a fact set
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// Send sends a notification to Teams
+func (t *TeamsNotifier) Send(ctx context.Context, n Notification) error {
+	text, err := t.templates.render(n)
+	if err != nil {
+		return err
+	}
+	message := t.buildMessage(n, text)
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Teams message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.config.WebhookURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notifier
+func (t *TeamsNotifier) Name() string {
+	return "Teams"
+}
+
+// httpClient returns the client used for requests
+func (t *TeamsNotifier) httpClient() *http.Client {
+	return t.client
+}
+
+// buildMessage constructs the Adaptive Card message
+func (t *TeamsNotifier) buildMessage(n Notification, text messageText) teamsMessage {
+	domainName := n.Domain.DisplayName()
+
+	style := teamsStyle(n.Severity())
+
+	var facts []teamsFact
+	for _, field := range messageFields(n) {
+		facts = append(facts, teamsFact{Title: field.Label, Value: field.Value})
+	}
+
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []interface{}{
+			teamsContainer{
+				Type:  "Container",
+				Style: style,
+				Bleed: true,
+				Items: []interface{}{
+					teamsTextBlock{
+						Type:   "TextBlock",
+						Text:   text.titleOr("⚠️ SSL Certificate Expiry Alert"),
+						Size:   "Large",
+						Weight: "Bolder",
+						Color:  style,
+						Wrap:   true,
+					},
+					teamsTextBlock{
+						Type: "TextBlock",
+						Text: text.bodyOr(expiryHeadline("**"+domainName+"**", n)),
+						Wrap: true,
+					},
+				},
+			},
+			teamsFactSet{
+				Type:  "FactSet",
+				Facts: facts,
+			},
+			teamsTextBlock{
+				Type:     "TextBlock",
+				Text:     "SSL Certificate Monitor",
+				Size:     "Small",
+				IsSubtle: true,
+			},
+		},
+		MSTeams: &teamsWidth{Width: "Full"},
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			},
+		},
+	}
+}
+
+// teamsStyle maps a severity to the Adaptive Card style matching its color
+func teamsStyle(s Severity) string {
+	switch s {
+	case SeverityExpired, SeverityCritical:
+		return "attention" // Red
+	case SeverityWarning:
+		return "warning" // Orange
+	default:
+		return "good" // Green
+	}
+}
@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestTeamsAdaptiveCard(t *testing.T) {
+	var message map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	tn, err := NewTeamsNotifier(config.TeamsConfig{WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Group = "web"
+	if err := tn.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if message["type"] != "message" {
+		t.Errorf("type = %v, want message", message["type"])
+	}
+	attachments, _ := message["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(attachments))
+	}
+	attachment := attachments[0].(map[string]interface{})
+	if got := attachment["contentType"]; got != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %v", got)
+	}
+	if _, ok := attachment["contentUrl"]; !ok {
+		t.Error("contentUrl is missing, want null")
+	}
+	card := attachment["content"].(map[string]interface{})
+	if card["type"] != "AdaptiveCard" || card["version"] != "1.4" {
+		t.Errorf("card type and version = %v %v, want AdaptiveCard 1.4", card["type"], card["version"])
+	}
+	if got := card["$schema"]; got != "http://adaptivecards.io/schemas/adaptive-card.json" {
+		t.Errorf("$schema = %v", got)
+	}
+
+	body := card["body"].([]interface{})
+	header := body[0].(map[string]interface{})
+	if header["style"] != "attention" {
+		t.Errorf("header style = %v, want attention", header["style"])
+	}
+	facts := map[string]interface{}{}
+	for _, fact := range body[1].(map[string]interface{})["facts"].([]interface{}) {
+		fact := fact.(map[string]interface{})
+		facts[fact["title"].(string)] = fact["value"]
+	}
+	want := map[string]string{
+		"Domain":         "a.example.com",
+		"Host":           "a.example.com:443",
+		"Days Remaining": "3.0",
+		"Threshold":      "7 days",
+		"Group":          "web",
+	}
+	for title, value := range want {
+		if facts[title] != value {
+			t.Errorf("fact %q = %v, want %q", title, facts[title], value)
+		}
+	}
+}
+
+func TestTeamsStyle(t *testing.T) {
+	tests := map[Severity]string{
+		SeverityExpired:  "attention",
+		SeverityCritical: "attention",
+		SeverityWarning:  "warning",
+		SeverityInfo:     "good",
+	}
+	for severity, want := range tests {
+		if got := teamsStyle(severity); got != want {
+			t.Errorf("teamsStyle(%s) = %q, want %q", severity, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func init() {
+	Register("telegram", config.TelegramConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.TelegramConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewTelegramNotifier(cfg)
+	})
+}
+
+// TelegramNotifier sends notifications through a Telegram bot
+type TelegramNotifier struct {
+	config    config.TelegramConfig
+	client    *http.Client
+	templates *messageTemplates
+}
+
+// NewTelegramNotifier creates a new Telegram notifier
+func NewTelegramNotifier(cfg config.TelegramConfig) (*TelegramNotifier, error) {
+	if cfg.BotToken == "" {
+		return nil, fmt.Errorf("bot token is required")
+	}
+	if len(cfg.Chats) == 0 {
+		return nil, fmt.Errorf("at least one chat is required")
+	}
+	for _, chat := range cfg.Chats {
+		if chat.ID == "" {
+			return nil, fmt.Errorf("chat ID is required")
+		}
+	}
+	config.ApplyDefaults(&cfg)
+	switch cfg.ParseMode {
+	case "MarkdownV2", "HTML":
+	default:
+		return nil, fmt.Errorf("unsupported parse mode %q (use MarkdownV2 or HTML)", cfg.ParseMode)
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelegramNotifier{
+		config:    cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}, nil
+}
+
+// telegramMessage represents the sendMessage request
+type telegramMessage struct {
+	ChatID              string `json:"chat_id"`
+	MessageThreadID     int    `json:"message_thread_id,omitempty"`
+	Text                string `json:"text"`
+	ParseMode           string `json:"parse_mode"`
+	DisableNotification bool   `json:"disable_notification,omitempty"`
+}
+
+// Send sends the notification to every configured chat
+func (t *TelegramNotifier) Send(ctx context.Context, n Notification) error {
+	rendered, err := t.templates.render(n)
+	if err != nil {
+		return err
+	}
+	text := t.buildMessage(n, rendered)
+
+	var errs []error
+	for _, chat := range t.config.Chats {
+		message := telegramMessage{
+			ChatID:              chat.ID,
+			MessageThreadID:     chat.ThreadID,
+			Text:                text,
+			ParseMode:           t.config.ParseMode,
+			DisableNotification: t.config.DisableNotification,
+		}
+		if err := t.sendMessage(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Name returns the name of the notifier
+func (t *TelegramNotifier) Name() string {
+	return "Telegram"
+}
+
+// httpClient returns the client used for requests
+func (t *TelegramNotifier) httpClient() *http.Client {
+	return t.client
+}
+
+// sendMessage calls the Bot API sendMessage method for a single chat
+func (t *TelegramNotifier) sendMessage(ctx context.Context, message telegramMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Telegram message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", t.config.APIURL, t.config.BotToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, don't leak it into logs
+		return fmt.Errorf("failed to send request: %s", strings.ReplaceAll(err.Error(), t.config.BotToken, "<token>"))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || !result.OK {
+		if result.Description != "" {
+			return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, result.Description)
+		}
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// buildMessage constructs the message text in the configured parse mode.
+// Rendered templates are used as-is and must be formatted for the parse mode.
+func (t *TelegramNotifier) buildMessage(n Notification, text messageText) string {
+	escape, bold := escapeMarkdownV2, func(s string) string { return "*" + s + "*" }
+	if t.config.ParseMode == "HTML" {
+		escape, bold = html.EscapeString, func(s string) string { return "<b>" + s + "</b>" }
+	}
+
+	var sb strings.Builder
+	sb.WriteString(text.titleOr("⚠️ "+bold(escape("SSL Certificate Expiry Alert"))) + "\n")
+	if text.Body != "" {
+		sb.WriteString(text.Body)
+	} else {
+		for _, field := range messageFields(n) {
+			sb.WriteString(bold(escape(field.Label+":")) + " " + escape(field.Value) + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// markdownV2Replacer escapes the characters reserved by Telegram MarkdownV2
+var markdownV2Replacer = strings.NewReplacer(
+	`\`, `\\`, `_`, `\_`, `*`, `\*`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`,
+	`~`, `\~`, "`", "\\`", `>`, `\>`, `#`, `\#`, `+`, `\+`, `-`, `\-`, `=`, `\=`,
+	`|`, `\|`, `{`, `\{`, `}`, `\}`, `.`, `\.`, `!`, `\!`,
+)
+
+// escapeMarkdownV2 escapes text for use in a MarkdownV2 message
+func escapeMarkdownV2(s string) string {
+	return markdownV2Replacer.Replace(s)
+}
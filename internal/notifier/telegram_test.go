@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestTelegramSend(t *testing.T) {
+	var got telegramMessage
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	n, err := NewTelegramNotifier(config.TelegramConfig{
+		BotToken: "123:secret",
+		APIURL:   srv.URL,
+		Chats:    []config.TelegramChat{{ID: "42", ThreadID: 7}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Send(context.Background(), Notification{
+		Domain:        config.DomainConfig{Host: "my-site.example.com", Port: 443},
+		DaysRemaining: 3.5,
+		Expiry:        time.Now(),
+		Threshold:     7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot123:secret/sendMessage" {
+		t.Errorf("path = %q", path)
+	}
+	if got.ChatID != "42" || got.MessageThreadID != 7 || got.ParseMode != "MarkdownV2" {
+		t.Errorf("message = %+v", got)
+	}
+	if !strings.Contains(got.Text, `my\-site\.example\.com`) {
+		t.Errorf("host not escaped for MarkdownV2: %q", got.Text)
+	}
+}
+
+func TestTelegramErrorRedactsToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	n, err := NewTelegramNotifier(config.TelegramConfig{
+		BotToken: "123:secret",
+		APIURL:   srv.URL,
+		Chats:    []config.TelegramChat{{ID: "42"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Send(context.Background(), Notification{Domain: config.DomainConfig{Host: "a.example.com"}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the bot token: %v", err)
+	}
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error lost its cause: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+// TemplateData is the data model available to message templates
+type TemplateData struct {
+	Domain        string            // host name, or certificate file followed by #alias for keystore entries (kept for existing webhook body templates)
+	Host          string            // host name, empty for certificate files
+	Port          int               // port
+	File          string            // certificate file, empty for hosts
+	Alias         string            // keystore entry, empty unless File is a keystore
+	Name          string            // configured display name, may be empty
+	DisplayName   string            // display name, falling back to the host
+	Endpoint      string            // host:port, or the file followed by #alias for keystore entries
+	URL           string            // https URL of the host, empty for certificate files
+	DaysRemaining float64           // days until expiry, negative once expired
+	Expiry        time.Time         // certificate expiry (NotAfter)
+	Threshold     int               // reminder threshold that triggered the notification
+	Thresholds    []int             // all reminder thresholds of the domain
+	Severity      string            // info, warning, critical or expired
+	Action        string            // recommended action for the severity
+	Group         string            // domain group
+	Tags          []string          // domain tags
+	Labels        map[string]string // domain labels
+	Certificate   config.CertificateInfo
+	CheckTime     time.Time
+}
+
+// newTemplateData builds the template data for a notification
+func newTemplateData(n Notification) TemplateData {
+	displayName := n.Domain.DisplayName()
+	return TemplateData{
+		Domain:        n.Domain.Key(),
+		Host:          n.Domain.Host,
+		Port:          n.Domain.Port,
+		File:          n.Domain.File,
+		Alias:         n.Domain.Alias,
+		Name:          n.Domain.Name,
+		DisplayName:   displayName,
+		Endpoint:      n.Endpoint(),
+		URL:           n.URL(),
+		DaysRemaining: n.DaysRemaining,
+		Expiry:        n.Expiry,
+		Threshold:     n.Threshold,
+		Thresholds:    n.Domain.ReminderDays,
+		Severity:      string(n.Severity()),
+		Action:        n.Severity().Action(),
+		Group:         n.Domain.Group,
+		Tags:          n.Domain.Tags,
+		Labels:        n.Domain.Labels,
+		Certificate:   n.Certificate,
+		CheckTime:     time.Now(),
+	}
+}
+
+// templateFuncs are the helper functions available to message templates
+var templateFuncs = map[string]interface{}{
+	// date formats a time with a Go layout, e.g. {{date "2006-01-02" .Expiry}}
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+	// rfc3339 formats a time as RFC 3339
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+	// until returns the duration until a time
+	"until": func(t time.Time) time.Duration { return time.Until(t) },
+	// humanizeDuration renders a duration as e.g. "3 days 4 hours"
+	"humanizeDuration": humanizeDuration,
+	// humanizeDays renders a number of days as e.g. "3 days 4 hours"
+	"humanizeDays": func(days float64) string {
+		return humanizeDuration(time.Duration(days * 24 * float64(time.Hour)))
+	},
+	"join":       func(sep string, s []string) string { return strings.Join(s, sep) },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"labels":     formatLabels,
+	"markdownV2": escapeMarkdownV2,
+	// json encodes a value as JSON, e.g. for webhook bodies
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// default returns the fallback when the value is empty
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// humanizeDuration renders a duration in days and hours, or hours and
+// minutes when under a day. Negative durations are prefixed with "-".
+func humanizeDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d/time.Hour) % 24
+	minutes := int64(math.Round(float64(d%time.Hour) / float64(time.Minute)))
+
+	switch {
+	case days > 0 && hours > 0:
+		return sign + plural(days, "day") + " " + plural(hours, "hour")
+	case days > 0:
+		return sign + plural(days, "day")
+	case hours > 0:
+		return sign + plural(hours, "hour") + " " + plural(minutes, "minute")
+	default:
+		return sign + plural(minutes, "minute")
+	}
+}
+
+// messageTemplates holds the compiled template overrides of a notifier
+type messageTemplates struct {
+	title    *template.Template
+	body     *template.Template
+	subject  *template.Template
+	htmlBody *htmltemplate.Template
+}
+
+// messageText holds rendered template overrides. Empty fields keep the
+// notifier's default wording.
+type messageText struct {
+	Title    string
+	Body     string
+	Subject  string
+	HTMLBody string
+}
+
+// newMessageTemplates compiles the templates of a notifier configuration
+func newMessageTemplates(cfg config.TemplateConfig) (*messageTemplates, error) {
+	t := &messageTemplates{}
+
+	sources := []struct {
+		name   string
+		inline string
+		file   string
+		out    **template.Template
+	}{
+		{"title", cfg.Title, cfg.TitleFile, &t.title},
+		{"body", cfg.Body, cfg.BodyFile, &t.body},
+		{"subject", cfg.Subject, cfg.SubjectFile, &t.subject},
+	}
+	for _, src := range sources {
+		text, err := templateSource(src.name, src.inline, src.file)
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			continue
+		}
+		*src.out, err = template.New(src.name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", src.name, err)
+		}
+	}
+
+	text, err := templateSource("html_body", cfg.HTMLBody, cfg.HTMLBodyFile)
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		t.htmlBody, err = htmltemplate.New("html_body").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse html_body template: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+// templateSource returns the inline template or the contents of its file.
+// Config loading has resolved relative file paths against the config file.
+func templateSource(name, inline, file string) (string, error) {
+	if inline != "" && file != "" {
+		return "", fmt.Errorf("%s and %s_file are mutually exclusive", name, name)
+	}
+	if file == "" {
+		return inline, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s template: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// render executes the configured templates for a notification
+func (t *messageTemplates) render(n Notification) (messageText, error) {
+	var text messageText
+	if t == nil {
+		return text, nil
+	}
+
+	data := newTemplateData(n)
+	targets := []struct {
+		tmpl *template.Template
+		out  *string
+	}{
+		{t.title, &text.Title},
+		{t.body, &text.Body},
+		{t.subject, &text.Subject},
+	}
+	for _, target := range targets {
+		if target.tmpl == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := target.tmpl.Execute(&buf, data); err != nil {
+			return text, fmt.Errorf("failed to execute %s template: %w", target.tmpl.Name(), err)
+		}
+		*target.out = buf.String()
+	}
+
+	if t.htmlBody != nil {
+		var buf bytes.Buffer
+		if err := t.htmlBody.Execute(&buf, data); err != nil {
+			return text, fmt.Errorf("failed to execute html_body template: %w", err)
+		}
+		text.HTMLBody = buf.String()
+	}
+
+	return text, nil
+}
+
+// titleOr returns the rendered title, or def when no title template is set
+func (m messageText) titleOr(def string) string {
+	if m.Title != "" {
+		return m.Title
+	}
+	return def
+}
+
+// bodyOr returns the rendered body, or def when no body template is set
+func (m messageText) bodyOr(def string) string {
+	if m.Body != "" {
+		return m.Body
+	}
+	return def
+}
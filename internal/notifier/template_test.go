@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	dir := t.TempDir()
+	bodyFile := filepath.Join(dir, "body.tmpl")
+	if err := os.WriteFile(bodyFile, []byte(`{{.DisplayName}} ({{.Endpoint}}) is {{.Severity}}: {{printf "%.0f" .DaysRemaining}} days`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := newMessageTemplates(config.TemplateConfig{
+		Title:    `{{upper .Severity}} {{.Domain}}`,
+		BodyFile: bodyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	n.Domain.Name = "Shop"
+	text, err := templates.render(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.Title != "CRITICAL a.example.com" {
+		t.Errorf("title = %q", text.Title)
+	}
+	if text.Body != "Shop (a.example.com:443) is critical: 3 days" {
+		t.Errorf("body = %q", text.Body)
+	}
+}
+
+func TestMessageTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TemplateConfig
+		want string
+	}{
+		{"inline and file", config.TemplateConfig{Body: "x", BodyFile: "x.tmpl"}, "body and body_file are mutually exclusive"},
+		{"missing file", config.TemplateConfig{TitleFile: filepath.Join(t.TempDir(), "missing.tmpl")}, "failed to read title template"},
+		{"syntax", config.TemplateConfig{Body: "{{.Domain"}, "failed to parse body template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newMessageTemplates(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package notifier
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/hadi/ssl-cert-monitor/internal/config"
@@ -15,9 +18,20 @@ import (
 
 // WebhookNotifier sends notifications to a generic webhook endpoint
 type WebhookNotifier struct {
-	config   config.WebhookConfig
-	client   *http.Client
-	template *template.Template
+	config    config.WebhookConfig
+	client    *http.Client
+	oauth2    *clientCredentials
+	templates *messageTemplates
+}
+
+func init() {
+	Register("webhook", config.WebhookConfig{}, func(def config.NotifierConfig, env Env) (Notifier, error) {
+		var cfg config.WebhookConfig
+		if err := def.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return NewWebhookNotifier(cfg)
+	})
 }
 
 // NewWebhookNotifier creates a new webhook notifier
@@ -25,70 +39,73 @@ func NewWebhookNotifier(cfg config.WebhookConfig) (*WebhookNotifier, error) {
 	if cfg.URL == "" {
 		return nil, fmt.Errorf("webhook URL is required")
 	}
-	if cfg.Method == "" {
-		cfg.Method = "POST"
-	}
+	config.ApplyDefaults(&cfg)
 
-	var tmpl *template.Template
+	// body_template predates templates and is the same as templates.body
 	if cfg.BodyTemplate != "" {
-		var err error
-		tmpl, err = template.New("webhook").Parse(cfg.BodyTemplate)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse body template: %w", err)
+		if cfg.Templates.Body != "" || cfg.Templates.BodyFile != "" {
+			return nil, fmt.Errorf("body_template and templates.body are mutually exclusive")
 		}
+		cfg.Templates.Body = cfg.BodyTemplate
+	}
+	templates, err := newMessageTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
 	}
 
-	return &WebhookNotifier{
-		config:   cfg,
-		client:   &http.Client{Timeout: 10 * time.Second},
-		template: tmpl,
-	}, nil
-}
+	if cfg.BasicAuth != nil && cfg.OAuth2 != nil {
+		return nil, fmt.Errorf("basic_auth and oauth2 are mutually exclusive")
+	}
 
-// webhookData represents the data available in the template
-type webhookData struct {
-	Domain        string
-	Host          string
-	Port          int
-	Name          string
-	DaysRemaining float64
-	Expiry        time.Time
-	Threshold     int
-	CheckTime     time.Time
+	client := &http.Client{Timeout: 10 * time.Second}
+	tlsConfig, err := clientTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	w := &WebhookNotifier{
+		config:    cfg,
+		client:    client,
+		templates: templates,
+	}
+	if cfg.OAuth2 != nil {
+		// The token endpoint is called with the same client, so client
+		// certificates are presented there too
+		w.oauth2, err = newClientCredentials(*cfg.OAuth2, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
 }
 
 // Send sends a notification to the webhook endpoint
 func (w *WebhookNotifier) Send(ctx context.Context, n Notification) error {
-	var body []byte
-	var err error
-
-	if w.template != nil {
-		data := webhookData{
-			Domain:        n.Domain.Host,
-			Host:          n.Domain.Host,
-			Port:          n.Domain.Port,
-			Name:          n.Domain.Name,
-			DaysRemaining: n.DaysRemaining,
-			Expiry:        n.Expiry,
-			Threshold:     n.Threshold,
-			CheckTime:     time.Now(),
-		}
+	text, err := w.templates.render(n)
+	if err != nil {
+		return err
+	}
 
-		var buf bytes.Buffer
-		if err := w.template.Execute(&buf, data); err != nil {
-			return fmt.Errorf("failed to execute template: %w", err)
-		}
-		body = buf.Bytes()
-	} else {
+	body := []byte(text.Body)
+	if text.Body == "" {
 		// Default JSON body
 		defaultBody := map[string]interface{}{
-			"domain":         n.Domain.Host,
+			"domain":         n.Domain.Key(),
 			"name":           n.Domain.Name,
 			"days_remaining": n.DaysRemaining,
 			"expiry":         n.Expiry.Format(time.RFC3339),
 			"threshold":      n.Threshold,
 			"check_time":     time.Now().Format(time.RFC3339),
-			"message":        fmt.Sprintf("SSL certificate for %s expires in %.1f days", n.Domain.Host, n.DaysRemaining),
+			"severity":       n.Severity(),
+			"group":          n.Domain.Group,
+			"tags":           n.Domain.Tags,
+			"labels":         n.Domain.Labels,
+			"message":        text.titleOr(expirySummary(n.Domain.Key(), n.DaysRemaining)),
 		}
 		body, err = json.Marshal(defaultBody)
 		if err != nil {
@@ -115,12 +132,20 @@ func (w *WebhookNotifier) Send(ctx context.Context, n Notification) error {
 		}
 	}
 
+	if err := w.authenticate(ctx, req, body); err != nil {
+		return err
+	}
+
 	resp, err := w.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized && w.oauth2 != nil {
+		// Fetch a fresh token next time in case this one was revoked
+		w.oauth2.Invalidate()
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -128,7 +153,44 @@ func (w *WebhookNotifier) Send(ctx context.Context, n Notification) error {
 	return nil
 }
 
+// authenticate adds the credentials and signature headers to the request.
+// They take precedence over static headers of the same name.
+func (w *WebhookNotifier) authenticate(ctx context.Context, req *http.Request, body []byte) error {
+	switch {
+	case w.config.BasicAuth != nil:
+		req.SetBasicAuth(w.config.BasicAuth.Username, w.config.BasicAuth.Password)
+	case w.oauth2 != nil:
+		token, err := w.oauth2.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get OAuth2 token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if w.config.Signing.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(w.config.Signing.TimestampHeader, timestamp)
+		req.Header.Set(w.config.Signing.SignatureHeader, "sha256="+signPayload(w.config.Signing.Secret, timestamp, body))
+	}
+	return nil
+}
+
+// signPayload returns the hex HMAC-SHA256 of "<timestamp>.<body>". Including
+// the timestamp lets receivers reject replayed requests.
+func signPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // Name returns the name of the notifier
 func (w *WebhookNotifier) Name() string {
 	return "Webhook"
-}
\ No newline at end of file
+}
+
+// httpClient returns the client used for requests
+func (w *WebhookNotifier) httpClient() *http.Client {
+	return w.client
+}
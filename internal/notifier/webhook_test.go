@@ -0,0 +1,193 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+)
+
+func TestWebhookSigning(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhookNotifier(config.WebhookConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Signature-256": "static"},
+		Signing: config.WebhookSigningConfig{Secret: "s3cret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Send(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := header.Get("X-Signature-Timestamp")
+	if timestamp == "" {
+		t.Fatal("no timestamp header")
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); header.Get("X-Signature-256") != want {
+		t.Errorf("signature = %q, want %q", header.Get("X-Signature-256"), want)
+	}
+}
+
+func TestWebhookOAuth2(t *testing.T) {
+	var fetches, rejects int32
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		r.ParseForm()
+		user, password, _ := r.BasicAuth()
+		if user != "id" || password != "se%3Ac" {
+			t.Errorf("client credentials = %q:%q, want them form-encoded", user, password)
+		}
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "a b" || r.Form.Get("audience") != "hooks" {
+			t.Errorf("token request form = %v", r.Form)
+		}
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, n)
+	}))
+	defer tokens.Close()
+
+	var auth []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		if atomic.LoadInt32(&rejects) > 0 {
+			atomic.AddInt32(&rejects, -1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer hook.Close()
+
+	w, err := NewWebhookNotifier(config.WebhookConfig{
+		URL: hook.URL,
+		OAuth2: &config.WebhookOAuth2Config{
+			TokenURL:       tokens.URL,
+			ClientID:       "id",
+			ClientSecret:   "se:c",
+			Scopes:         []string{"a", "b"},
+			EndpointParams: map[string]string{"audience": "hooks"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testNotification()
+	for i := 0; i < 2; i++ {
+		if err := w.Send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("token fetched %d times, want once", fetches)
+	}
+
+	// A rejected token is replaced on the next send
+	atomic.StoreInt32(&rejects, 1)
+	if err := w.Send(context.Background(), n); err == nil {
+		t.Error("send with a rejected token succeeded")
+	}
+	if err := w.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	want := "Bearer token-1 Bearer token-1 Bearer token-1 Bearer token-2"
+	if got := strings.Join(auth, " "); got != want {
+		t.Errorf("authorization = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookOAuth2Error(t *testing.T) {
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_client","error_description":"unknown client"}`)
+	}))
+	defer tokens.Close()
+
+	w, err := NewWebhookNotifier(config.WebhookConfig{
+		URL:    "http://127.0.0.1:1",
+		OAuth2: &config.WebhookOAuth2Config{TokenURL: tokens.URL, ClientID: "id"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Send(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "invalid_client: unknown client") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestWebhookCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWebhookNotifier(config.WebhookConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Send(context.Background(), testNotification()); err == nil {
+		t.Error("send to an untrusted server succeeded")
+	}
+
+	w, err = NewWebhookNotifier(config.WebhookConfig{URL: srv.URL, TLS: config.ClientTLSConfig{CAFile: caFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Send(context.Background(), testNotification()); err != nil {
+		t.Errorf("send with the CA file failed: %v", err)
+	}
+}
+
+func TestNewWebhookNotifierErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.WebhookConfig
+		want string
+	}{
+		{"no url", config.WebhookConfig{}, "webhook URL is required"},
+		{
+			"basic auth and oauth2",
+			config.WebhookConfig{
+				URL:       "https://example.com",
+				BasicAuth: &config.WebhookBasicAuth{Username: "u"},
+				OAuth2:    &config.WebhookOAuth2Config{TokenURL: "https://example.com/token", ClientID: "id"},
+			},
+			"mutually exclusive",
+		},
+		{"oauth2 without client id", config.WebhookConfig{URL: "https://example.com", OAuth2: &config.WebhookOAuth2Config{TokenURL: "https://example.com/token"}}, "client_id is required"},
+		{"cert without key", config.WebhookConfig{URL: "https://example.com", TLS: config.ClientTLSConfig{CertFile: "client.pem"}}, "must be set together"},
+		{"missing cert", config.WebhookConfig{URL: "https://example.com", TLS: config.ClientTLSConfig{CertFile: "/nonexistent.pem", KeyFile: "/nonexistent.key"}}, "failed to load client certificate"},
+		{"missing ca", config.WebhookConfig{URL: "https://example.com", TLS: config.ClientTLSConfig{CAFile: "/nonexistent.pem"}}, "failed to read CA file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookNotifier(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// Docs maps "Type" and "Type.Field" to the comment of a type or field
+type Docs map[string]string
+
+// LoadDocs reads the type and field comments of the Go package in dir.
+// A field's trailing comment is preferred over the one above it, which
+// often describes a group of fields.
+func LoadDocs(dir string) (Docs, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	docs := make(Docs)
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				docs.add(typeSpec.Name.Name, doc)
+
+				st, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					doc := field.Comment
+					if doc == nil {
+						doc = field.Doc
+					}
+					for _, name := range field.Names {
+						docs.add(typeSpec.Name.Name+"."+name.Name, doc)
+					}
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+// add records a comment as a single line
+func (d Docs) add(key string, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	if text := strings.Join(strings.Fields(doc.Text()), " "); text != "" {
+		d[key] = text
+	}
+}
@@ -0,0 +1,321 @@
+// Package schema generates a JSON Schema of the configuration file format
+// for editor autocompletion and validation. Descriptions are taken from the
+// comments of the config types, defaults and allowed values from their
+// default and enum struct tags.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hadi/ssl-cert-monitor/internal/config"
+	"github.com/hadi/ssl-cert-monitor/internal/notifier"
+)
+
+// Schema is a JSON Schema (draft-07) node
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// required lists the keys that must be set, by config type
+var required = map[string][]string{
+	"DomainSource": {"type", "path"},
+}
+
+// alternatives lists keys of which at least one must be set, by config type
+var alternatives = map[string][]string{
+	"DomainConfig": {"host", "file"},
+}
+
+// Generate returns the JSON Schema of the configuration file. Docs holds the
+// comments of the config types, see LoadDocs.
+func Generate(docs Docs) ([]byte, error) {
+	g := &generator{docs: docs, defs: make(map[string]*Schema)}
+	g.defs["variable"] = &Schema{
+		Description: "Environment variable reference, resolved when the configuration is loaded",
+		Type:        "string",
+		Pattern:     `^\$\{[^}]+\}$`,
+	}
+
+	root := g.object(reflect.TypeOf(config.Config{}), reflect.ValueOf(*config.DefaultConfig()))
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.ID = "https://github.com/hadi/ssl-cert-monitor/config.schema.json"
+	root.Title = "SSL Certificate Monitor configuration"
+	root.Definitions = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// generator builds schemas, collecting a definition per config struct
+type generator struct {
+	docs Docs
+	defs map[string]*Schema
+}
+
+// schemaFor returns the schema of a Go type. Def is its default value, and
+// may be invalid when there is none.
+func (g *generator) schemaFor(t reflect.Type, def reflect.Value) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if def.IsValid() {
+			def = def.Elem()
+		}
+	}
+
+	// Types with their own YAML decoding
+	switch t {
+	case reflect.TypeOf(config.NotificationsConfig{}):
+		return g.notifications()
+	case reflect.TypeOf(config.AddressList{}):
+		return &Schema{AnyOf: []*Schema{
+			{Type: "string", Description: "Comma-separated addresses"},
+			{Type: "array", Items: &Schema{Type: "string"}},
+		}}
+	case reflect.TypeOf(config.TelegramChat{}):
+		return &Schema{AnyOf: []*Schema{
+			{Type: "string", Description: "Chat ID or @channelusername"},
+			{Type: "integer", Description: "Chat ID"},
+			g.ref(t, def),
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.ref(t, def)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem(), reflect.Value{})}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return g.scalar("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return g.scalar("integer")
+	case reflect.Float32, reflect.Float64:
+		return g.scalar("number")
+	}
+	return &Schema{}
+}
+
+// scalar returns a schema of a non-string type that also accepts a ${VAR}
+// reference, which is only resolved when the file is loaded
+func (g *generator) scalar(typ string) *Schema {
+	return &Schema{AnyOf: []*Schema{{Type: typ}, {Ref: "#/definitions/variable"}}}
+}
+
+// ref returns a reference to the definition of a struct, creating it first
+func (g *generator) ref(t reflect.Type, def reflect.Value) *Schema {
+	name := t.Name()
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = nil // reserve the name for recursive types
+		g.defs[name] = g.object(t, def)
+	}
+	return &Schema{Ref: "#/definitions/" + name}
+}
+
+// object returns the schema of a struct. Unknown keys are not allowed, as
+// in the configuration file.
+func (g *generator) object(t reflect.Type, def reflect.Value) *Schema {
+	s := &Schema{
+		Description:          sentence(g.docs[t.Name()]),
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+		Required:             required[t.Name()],
+	}
+	for _, key := range alternatives[t.Name()] {
+		s.AnyOf = append(s.AnyOf, &Schema{Required: []string{key}})
+	}
+	g.fields(s, t, def)
+	return s
+}
+
+// fields adds the properties of the fields of struct t to s
+func (g *generator) fields(s *Schema, t reflect.Type, def reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		var fieldDef reflect.Value
+		if def.IsValid() {
+			fieldDef = def.Field(i)
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			g.fields(s, field.Type, fieldDef)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		doc := g.docs[t.Name()+"."+field.Name]
+		prop := g.schemaFor(field.Type, fieldDef)
+		if values, ok := field.Tag.Lookup("enum"); ok {
+			prop = g.enum(strings.Split(values, ","), field.Tag.Get("ignorecase") == "true")
+		}
+		if prop.Ref != "" && doc != "" {
+			// Siblings of $ref are ignored, so wrap it to keep the description
+			prop = &Schema{AllOf: []*Schema{prop}}
+		}
+		prop.Description = sentence(doc)
+		if fieldDef.IsValid() && !fieldDef.IsZero() && field.Type.Kind() != reflect.Struct {
+			prop.Default = fieldDef.Interface()
+		} else if value, ok := field.Tag.Lookup("default"); ok {
+			prop.Default = defaultValue(field, value)
+		}
+		s.Properties[name] = prop
+
+		if field.Type.Kind() == reflect.String && config.IsSecretKey(name) {
+			s.Properties[name+"_file"] = &Schema{
+				Description: fmt.Sprintf("File to read %s from, instead of setting it directly", name),
+				Type:        "string",
+			}
+		}
+	}
+}
+
+// notifications returns the schema of the notifications section: either a
+// list of named notifiers of any type, or one block per type
+func (g *generator) notifications() *Schema {
+	types := notifier.Types()
+	byType := &Schema{
+		Description:          "One notifier per type, named after the type",
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	item := &Schema{
+		Type:     "object",
+		Required: []string{"type"},
+		Properties: map[string]*Schema{
+			"type": {Description: "Notifier type", Type: "string", Enum: stringsToValues(types)},
+		},
+	}
+
+	for _, typ := range types {
+		settings := g.ref(notifier.SettingsType(typ), reflect.Value{})
+		byType.Properties[typ] = settings
+
+		// The list layout adds name and type to the type's settings
+		def := g.defs[notifier.SettingsType(typ).Name()]
+		entry := &Schema{
+			Description:          def.Description,
+			Type:                 "object",
+			Properties:           make(map[string]*Schema, len(def.Properties)+2),
+			AdditionalProperties: false,
+		}
+		for key, prop := range def.Properties {
+			entry.Properties[key] = prop
+		}
+		entry.Properties["name"] = &Schema{Description: "Name used by routes and domains, defaults to the type", Type: "string"}
+		entry.Properties["type"] = &Schema{Const: typ}
+		entry.Properties["enabled"] = &Schema{
+			Description: "Set to false to disable the notifier",
+			AnyOf:       []*Schema{{Type: "boolean"}, {Ref: "#/definitions/variable"}},
+			Default:     true,
+		}
+		g.defs["notifier."+typ] = entry
+
+		item.AllOf = append(item.AllOf, &Schema{
+			If:   &Schema{Properties: map[string]*Schema{"type": {Const: typ}}},
+			Then: &Schema{Ref: "#/definitions/notifier." + typ},
+		})
+	}
+
+	return &Schema{AnyOf: []*Schema{
+		{Description: "Named notifiers", Type: "array", Items: item},
+		byType,
+	}}
+}
+
+// enum returns the schema of a string with a fixed set of values. Matching
+// ignores case when the config loader does, and a ${VAR} reference is
+// accepted since it is only resolved when the file is loaded.
+func (g *generator) enum(values []string, ignoreCase bool) *Schema {
+	value := &Schema{Type: "string", Enum: stringsToValues(values)}
+	if ignoreCase {
+		patterns := make([]string, len(values))
+		for i, v := range values {
+			patterns[i] = caseInsensitive(v)
+		}
+		value = &Schema{Type: "string", Pattern: "^(" + strings.Join(patterns, "|") + ")$"}
+	}
+	return &Schema{AnyOf: []*Schema{value, {Ref: "#/definitions/variable"}}}
+}
+
+// caseInsensitive returns a regular expression matching s in any case, as
+// JSON Schema patterns have no flags
+func caseInsensitive(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		upper, lower := strings.ToUpper(string(r)), strings.ToLower(string(r))
+		switch {
+		case upper != lower:
+			b.WriteString("[" + upper + lower + "]")
+		case strings.ContainsRune(`\.+*?()|[]{}^$`, r):
+			b.WriteString(`\` + string(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// defaultValue converts the default tag of a field to the field's type
+func defaultValue(field reflect.StructField, value string) interface{} {
+	switch field.Type.Kind() {
+	case reflect.Int:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		panic(fmt.Sprintf("schema: invalid default %q of %s", value, field.Name))
+	}
+	return value
+}
+
+// sentence capitalizes the first letter of a comment
+func sentence(doc string) string {
+	if doc == "" {
+		return ""
+	}
+	return strings.ToUpper(doc[:1]) + doc[1:]
+}
+
+// stringsToValues converts a string slice for use as an enum
+func stringsToValues(list []string) []interface{} {
+	values := make([]interface{}, len(list))
+	for i, s := range list {
+		values[i] = s
+	}
+	return values
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestSchemaUpToDate fails when config.schema.json no longer matches the
+// config types; run make schema to regenerate it
+func TestSchemaUpToDate(t *testing.T) {
+	docs, err := LoadDocs("../config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Generate(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := os.ReadFile("../../config.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(current, data) {
+		t.Error("config.schema.json is out of date, run make schema")
+	}
+}
+
+func TestSchemaEnums(t *testing.T) {
+	docs, err := LoadDocs("../config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Generate(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root struct {
+		Definitions map[string]struct {
+			Properties map[string]*Schema `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	tls := root.Definitions["EmailConfig"].Properties["tls"]
+	if len(tls.AnyOf) != 2 || len(tls.AnyOf[0].Enum) != 3 || tls.AnyOf[1].Ref != "#/definitions/variable" {
+		t.Errorf("email tls = %+v", tls)
+	}
+
+	region := root.Definitions["OpsgenieConfig"].Properties["region"]
+	if region.Default != "us" || len(region.AnyOf) != 2 {
+		t.Fatalf("opsgenie region = %+v", region)
+	}
+	pattern := regexp.MustCompile(region.AnyOf[0].Pattern)
+	for value, want := range map[string]bool{"us": true, "EU": true, "Eu": true, "de": false, "usa": false} {
+		if got := pattern.MatchString(value); got != want {
+			t.Errorf("region %q matches = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	if got, want := caseInsensitive("m.notice-2"), `[Mm]\.[Nn][Oo][Tt][Ii][Cc][Ee]-2`; got != want {
+		t.Errorf("caseInsensitive = %q, want %q", got, want)
+	}
+}
+
+// TestSchemaDefaultsDocumented fails when a field's comment names a default
+// other than the one its default tag applies
+func TestSchemaDefaultsDocumented(t *testing.T) {
+	docs, err := LoadDocs("../config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Generate(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root Schema
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	// A literal default in a comment: a number, a path, a URL or a quoted value
+	literal := regexp.MustCompile(`defaults? to ("[^"]*"|[^\s,]*[0-9/][^\s,]*)`)
+	var walk func(path string, s *Schema)
+	walk = func(path string, s *Schema) {
+		for name, prop := range s.Properties {
+			walk(path+"."+name, prop)
+		}
+		if !strings.Contains(strings.ToLower(s.Description), "default") {
+			return
+		}
+		switch value := s.Default.(type) {
+		case string, float64:
+			if want := fmt.Sprint(value); !strings.Contains(s.Description, want) {
+				t.Errorf("%s: description %q does not mention the default %s", path, s.Description, want)
+			}
+		case nil:
+			if m := literal.FindString(s.Description); m != "" {
+				t.Errorf("%s: description says %q without a default tag", path, m)
+			}
+		}
+	}
+	walk("config", &root)
+	for name, def := range root.Definitions {
+		walk(name, def)
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
 // State represents the persistent state of notifications
 type State struct {
-	Entries map[string]map[int]time.Time `json:"entries"` // domain -> threshold -> last sent time
+	Entries   map[string]map[int]time.Time `json:"entries"`             // domain -> threshold -> last sent time
+	Notifiers map[string]map[int][]string  `json:"notifiers,omitempty"` // domain -> threshold -> notifiers sent to
+	Failures  map[string][]string          `json:"failures,omitempty"`  // domain -> notifiers alerted of a failed check
+	Threads   map[string]string            `json:"threads,omitempty"`   // thread key -> message thread ID
 }
 
 // Manager handles state persistence
@@ -26,7 +30,10 @@ func NewManager(filePath string, cooldownHours int) (*Manager, error) {
 		filePath:      filePath,
 		cooldownHours: cooldownHours,
 		state: &State{
-			Entries: make(map[string]map[int]time.Time),
+			Entries:   make(map[string]map[int]time.Time),
+			Notifiers: make(map[string]map[int][]string),
+			Failures:  make(map[string][]string),
+			Threads:   make(map[string]string),
 		},
 	}
 
@@ -56,6 +63,18 @@ func (m *Manager) load() error {
 	}
 
 	// Ensure nested maps exist
+	if m.state.Entries == nil {
+		m.state.Entries = make(map[string]map[int]time.Time)
+	}
+	if m.state.Notifiers == nil {
+		m.state.Notifiers = make(map[string]map[int][]string)
+	}
+	if m.state.Failures == nil {
+		m.state.Failures = make(map[string][]string)
+	}
+	if m.state.Threads == nil {
+		m.state.Threads = make(map[string]string)
+	}
 	for domain, thresholds := range m.state.Entries {
 		if thresholds == nil {
 			m.state.Entries[domain] = make(map[int]time.Time)
@@ -105,6 +124,12 @@ func (m *Manager) save() error {
 
 // ShouldSend checks if a notification should be sent for a domain and threshold
 func (m *Manager) ShouldSend(domain string, threshold int) bool {
+	return m.ShouldSendWithCooldown(domain, threshold, m.cooldownHours)
+}
+
+// ShouldSendWithCooldown is like ShouldSend but uses the given cooldown
+// instead of the manager's default
+func (m *Manager) ShouldSendWithCooldown(domain string, threshold int, cooldownHours int) bool {
 	domainMap, exists := m.state.Entries[domain]
 	if !exists {
 		return true
@@ -115,22 +140,110 @@ func (m *Manager) ShouldSend(domain string, threshold int) bool {
 		return true
 	}
 
-	cooldown := time.Duration(m.cooldownHours) * time.Hour
+	cooldown := time.Duration(cooldownHours) * time.Hour
 	return time.Since(lastSent) > cooldown
 }
 
 // MarkSent records that a notification was sent for a domain and threshold
-func (m *Manager) MarkSent(domain string, threshold int) error {
+// to the named notifiers. No names stands for all notifiers.
+func (m *Manager) MarkSent(domain string, threshold int, notifiers []string) error {
 	if _, exists := m.state.Entries[domain]; !exists {
 		m.state.Entries[domain] = make(map[int]time.Time)
 	}
 	m.state.Entries[domain][threshold] = time.Now()
+	if _, exists := m.state.Notifiers[domain]; !exists {
+		m.state.Notifiers[domain] = make(map[int][]string)
+	}
+	m.state.Notifiers[domain][threshold] = notifiers
+	return m.save()
+}
+
+// SentTo returns the notifiers the last notification for a domain and
+// threshold was sent to. No names stands for all notifiers, as do state
+// files written before the names were recorded.
+func (m *Manager) SentTo(domain string, threshold int) []string {
+	return m.state.Notifiers[domain][threshold]
+}
+
+// Thresholds returns the thresholds a notification was sent for on a domain
+func (m *Manager) Thresholds(domain string) []int {
+	var thresholds []int
+	for threshold := range m.state.Entries[domain] {
+		thresholds = append(thresholds, threshold)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// ClearSent forgets that a notification was sent for a domain and threshold
+func (m *Manager) ClearSent(domain string, threshold int) error {
+	domainMap, exists := m.state.Entries[domain]
+	if !exists {
+		return nil
+	}
+	delete(domainMap, threshold)
+	if len(domainMap) == 0 {
+		delete(m.state.Entries, domain)
+	}
+	if notifiers, exists := m.state.Notifiers[domain]; exists {
+		delete(notifiers, threshold)
+		if len(notifiers) == 0 {
+			delete(m.state.Notifiers, domain)
+		}
+	}
+	return m.save()
+}
+
+// MarkFailed records that the named notifiers were alerted of a failed
+// check for a domain
+func (m *Manager) MarkFailed(domain string, notifiers []string) error {
+	m.state.Failures[domain] = notifiers
+	return m.save()
+}
+
+// Failed returns the notifiers alerted of a failed check for a domain, and
+// whether there is such an alert
+func (m *Manager) Failed(domain string) ([]string, bool) {
+	notifiers, exists := m.state.Failures[domain]
+	return notifiers, exists
+}
+
+// ClearFailed forgets the failed check alert for a domain
+func (m *Manager) ClearFailed(domain string) error {
+	if _, exists := m.state.Failures[domain]; !exists {
+		return nil
+	}
+	delete(m.state.Failures, domain)
+	return m.save()
+}
+
+// GetThread returns the message thread ID stored under key
+func (m *Manager) GetThread(key string) (string, bool) {
+	id, exists := m.state.Threads[key]
+	return id, exists
+}
+
+// SetThread stores a message thread ID under key
+func (m *Manager) SetThread(key, id string) error {
+	m.state.Threads[key] = id
+	return m.save()
+}
+
+// DeleteThread forgets the message thread ID stored under key
+func (m *Manager) DeleteThread(key string) error {
+	if _, exists := m.state.Threads[key]; !exists {
+		return nil
+	}
+	delete(m.state.Threads, key)
 	return m.save()
 }
 
 // Clear removes all state entries
 func (m *Manager) Clear() error {
 	m.state.Entries = make(map[string]map[int]time.Time)
+	m.state.Notifiers = make(map[string]map[int][]string)
+	m.state.Failures = make(map[string][]string)
+	m.state.Threads = make(map[string]string)
 	return m.save()
 }
 
@@ -142,4 +255,4 @@ func (m *Manager) GetLastSent(domain string, threshold int) (time.Time, bool) {
 	}
 	lastSent, exists := domainMap[threshold]
 	return lastSent, exists
-}
\ No newline at end of file
+}